@@ -0,0 +1,65 @@
+// Command sweep runs a parameter sweep headlessly and writes a CSV of the
+// final metrics for every configuration and replicate.
+//
+//	go run ./cmd/sweep -spec sweep.json -out results.csv
+package main
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"log/slog"
+	"os"
+	"swarmlings/config"
+	"swarmlings/experiment"
+	"time"
+)
+
+func main() {
+	specPath := flag.String("spec", "sweep.json", "sweep specification")
+	outPath := flag.String("out", "", "results CSV (default stdout)")
+	flag.Parse()
+
+	base, err := config.Load()
+	var verrs config.ValidationErrors
+	if errors.As(err, &verrs) {
+		slog.Warn("config has invalid values", "err", err)
+	} else if err != nil {
+		fatal("config", err)
+	}
+
+	sw, err := experiment.LoadSweep(*specPath, base)
+	if err != nil {
+		fatal("sweep spec", err)
+	}
+	points, err := sw.Points()
+	if err != nil {
+		fatal("sweep spec", err)
+	}
+	slog.Info("sweep starting", "points", len(points), "replicates", sw.Replicates, "ticks", sw.Ticks)
+
+	start := time.Now()
+	rows, err := sw.Run()
+	if err != nil {
+		fatal("sweep", err)
+	}
+	slog.Info("sweep finished", "runs", len(rows), "elapsed", time.Since(start).Round(time.Millisecond))
+
+	var out io.Writer = os.Stdout
+	if *outPath != "" {
+		f, err := os.Create(*outPath)
+		if err != nil {
+			fatal("results", err)
+		}
+		defer f.Close()
+		out = f
+	}
+	if err := sw.WriteCSV(out, rows); err != nil {
+		fatal("results", err)
+	}
+}
+
+func fatal(msg string, err error) {
+	slog.Error(msg, "err", err)
+	os.Exit(1)
+}
@@ -0,0 +1,77 @@
+// Command tune searches config values headlessly for a flock that meets the
+// targets in a tuning spec, and saves it as a preset if one does.
+//
+//	go run ./cmd/tune -spec tune.json -preset "polarized clusters"
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"log/slog"
+	"os"
+	"swarmlings/config"
+	"swarmlings/experiment"
+)
+
+func main() {
+	specPath := flag.String("spec", "tune.json", "tuning specification")
+	preset := flag.String("preset", "tuned", "name of the preset to save a config meeting every target under (empty to skip)")
+	flag.Parse()
+
+	base, err := config.Load()
+	var verrs config.ValidationErrors
+	if errors.As(err, &verrs) {
+		slog.Warn("config has invalid values", "err", err)
+	} else if err != nil {
+		fatal("config", err)
+	}
+
+	tn, err := experiment.LoadTuning(*specPath, base)
+	if err != nil {
+		fatal("tuning spec", err)
+	}
+	best, err := tn.Tune(func(gen int, best experiment.Candidate) {
+		slog.Info("generation done", "generation", gen+1, "of", tn.Generations, "best_score", best.Score)
+	})
+	if err != nil {
+		fatal("tune", err)
+	}
+	// Invalid candidates score +Inf and have no results to average.
+	if len(best.Results) > 0 {
+		for _, t := range tn.Targets {
+			var sum float64
+			for _, r := range best.Results {
+				v, _ := r.Metric(t.Metric)
+				sum += v
+			}
+			slog.Info("best config", "metric", t.Metric, "value", sum/float64(len(best.Results)))
+		}
+	}
+
+	switch {
+	case best.Score > 0:
+		slog.Warn("no config met every target, not saving a preset", "best_score", best.Score)
+	case *preset != "":
+		presets, err := config.LoadPresets()
+		if err != nil {
+			// Saving now would overwrite the presets that couldn't be read.
+			fatal("presets", err)
+		}
+		if err := config.SavePresets(presets.Set(*preset, best.Config)); err != nil {
+			fatal("presets", err)
+		}
+		slog.Info("preset saved", "name", *preset)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(best.Config); err != nil {
+		fatal("output", err)
+	}
+}
+
+func fatal(msg string, err error) {
+	slog.Error(msg, "err", err)
+	os.Exit(1)
+}
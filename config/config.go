@@ -2,12 +2,24 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
 	"os"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"swarmlings/sim"
 )
 
 const configPath = "config.json"
 
+const msgUnknownKey = "unknown key"
+
 type Config struct {
+	Version         int     `json:"version"`
 	AvoidanceFactor float64 `json:"avoidance_factor"`
 	AlignmentFactor float64 `json:"alignment_factor"`
 	GatheringFactor float64 `json:"gathering_factor"`
@@ -16,10 +28,16 @@ type Config struct {
 	MaxSpeed        float64 `json:"max_speed"`
 	WallMargin      float64 `json:"wall_margin"`
 	WallForce       float64 `json:"wall_force"`
+	ColorScheme     string  `json:"color_scheme"`
 }
 
+// ColorSchemes lists the valid ColorScheme values, in the order the UI
+// cycles through them.
+var ColorSchemes = []string{"plain", "heading", "speed", "density", "flock"}
+
 func Default() Config {
 	return Config{
+		Version:         CurrentVersion,
 		AvoidanceFactor: 1.0,
 		AlignmentFactor: 0.003,
 		GatheringFactor: 0.0005,
@@ -28,23 +46,232 @@ func Default() Config {
 		MaxSpeed:        3,
 		WallMargin:      75,
 		WallForce:       1.5,
+		ColorScheme:     "plain",
 	}
 }
 
-func Load() Config {
-	data, err := os.ReadFile(configPath)
+// Apply copies the simulation parameters into the world.
+func (c Config) Apply(w *sim.World) {
+	w.AvoidanceFactor = c.AvoidanceFactor
+	w.AlignmentFactor = c.AlignmentFactor
+	w.GatheringFactor = c.GatheringFactor
+	w.AvoidanceRadius = c.AvoidanceRadius
+	w.DetectionRadius = c.DetectionRadius
+	w.MaxSpeed = c.MaxSpeed
+	w.WallMargin = c.WallMargin
+	w.WallForce = c.WallForce
+}
+
+// jsonKeys holds the JSON key of each Config field, by field index.
+var jsonKeys = func() []string {
+	t := reflect.TypeOf(Config{})
+	keys := make([]string, t.NumField())
+	for i := range keys {
+		keys[i], _, _ = strings.Cut(t.Field(i).Tag.Get("json"), ",")
+	}
+	return keys
+}()
+
+// Get returns the parameter stored under the JSON key field.
+func (c Config) Get(field string) (float64, error) {
+	v, err := c.field(field)
 	if err != nil {
+		return 0, err
+	}
+	return v.Float(), nil
+}
+
+// Set stores v in the parameter with the JSON key field.
+func (c *Config) Set(field string, v float64) error {
+	f, err := c.field(field)
+	if err != nil {
+		return err
+	}
+	f.SetFloat(v)
+	return nil
+}
+
+func (c *Config) field(name string) (reflect.Value, error) {
+	i := slices.Index(jsonKeys, name)
+	if i < 0 {
+		return reflect.Value{}, fmt.Errorf("unknown parameter %q", name)
+	}
+	f := reflect.ValueOf(c).Elem().Field(i)
+	if f.Kind() != reflect.Float64 {
+		return reflect.Value{}, fmt.Errorf("%s is not a tunable parameter", name)
+	}
+	return f, nil
+}
+
+// FieldError describes a single invalid or unknown config key. Field is the
+// JSON key as it appears in config.json.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Has reports whether any error refers to field.
+func (e ValidationErrors) Has(field string) bool {
+	for _, fe := range e {
+		if fe.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks every field and returns a ValidationErrors listing each
+// problem, or nil if the config is usable.
+func (c Config) Validate() error {
+	var errs ValidationErrors
+	if c.Version < 0 || c.Version > CurrentVersion {
+		errs = append(errs, FieldError{"version", fmt.Sprintf("unsupported version %d (latest is %d)", c.Version, CurrentVersion)})
+	}
+	check := func(field string, v float64, positive bool) {
+		switch {
+		case math.IsNaN(v) || math.IsInf(v, 0):
+			errs = append(errs, FieldError{field, "must be a finite number"})
+		case positive && v <= 0:
+			errs = append(errs, FieldError{field, "must be greater than 0"})
+		case v < 0:
+			errs = append(errs, FieldError{field, "must not be negative"})
+		}
+	}
+	check("avoidance_factor", c.AvoidanceFactor, false)
+	check("alignment_factor", c.AlignmentFactor, false)
+	check("gathering_factor", c.GatheringFactor, false)
+	check("avoidance_radius", c.AvoidanceRadius, false)
+	check("detection_radius", c.DetectionRadius, true)
+	check("max_speed", c.MaxSpeed, true)
+	check("wall_margin", c.WallMargin, true)
+	check("wall_force", c.WallForce, false)
+
+	// Neighbors are only gathered from grid cells sized by the detection
+	// radius, so a larger avoidance radius would be silently truncated.
+	if !errs.Has("avoidance_radius") && !errs.Has("detection_radius") && c.AvoidanceRadius > c.DetectionRadius {
+		errs = append(errs, FieldError{"avoidance_radius", "must not exceed detection_radius"})
+	}
+	if !slices.Contains(ColorSchemes, c.ColorScheme) {
+		errs = append(errs, FieldError{"color_scheme", fmt.Sprintf("must be one of %s", strings.Join(ColorSchemes, ", "))})
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// Sanitize returns c with every field that fails Validate reset to its
+// Default value, or Default itself if that still leaves c invalid.
+func (c Config) Sanitize() Config {
+	var errs ValidationErrors
+	if !errors.As(c.Validate(), &errs) {
+		return c
+	}
+	v, def := reflect.ValueOf(&c).Elem(), reflect.ValueOf(Default())
+	for i, key := range jsonKeys {
+		if errs.Has(key) {
+			v.Field(i).Set(def.Field(i))
+		}
+	}
+	// Resetting one side of a cross-field check may not satisfy it, e.g. a
+	// default avoidance_radius above a small detection_radius.
+	if c.Validate() != nil {
 		return Default()
 	}
+	return c
+}
+
+func Load() (Config, error) {
+	return LoadFile(configPath)
+}
+
+// LoadFile reads path on top of Default. A missing file is not an error.
+// If the file cannot be parsed, Default is returned along with the error.
+// Unknown keys and invalid values are reported as ValidationErrors, in which
+// case the decoded config is still returned so the caller can decide what to
+// do with it.
+func LoadFile(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		slog.Debug("config file not found, using defaults", "path", path)
+		return Default(), nil
+	}
+	if err != nil {
+		return Default(), err
+	}
+	cfg, err := decodeConfig(data)
+	var verrs ValidationErrors
+	if err != nil && !errors.As(err, &verrs) {
+		return cfg, fmt.Errorf("parse %s: %w", path, err)
+	}
+	slog.Debug("config loaded", "path", path)
+	return cfg, err
+}
+
+// decodeConfig migrates, decodes and validates a single config document.
+func decodeConfig(data []byte) (Config, error) {
+	data, err := Migrate(data)
+	if err != nil {
+		return Default(), fmt.Errorf("migrate: %w", err)
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return Default(), err
+	}
 	cfg := Default()
-	json.Unmarshal(data, &cfg)
-	return cfg
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return Default(), err
+	}
+
+	var errs ValidationErrors
+	var unknown []string
+	for k := range raw {
+		if !slices.Contains(jsonKeys, k) {
+			unknown = append(unknown, k)
+		}
+	}
+	sort.Strings(unknown)
+	for _, k := range unknown {
+		errs = append(errs, FieldError{k, msgUnknownKey})
+	}
+	var verrs ValidationErrors
+	if errors.As(cfg.Validate(), &verrs) {
+		errs = append(errs, verrs...)
+	}
+	if len(errs) > 0 {
+		return cfg, errs
+	}
+	return cfg, nil
 }
 
-func Save(cfg Config) {
+func Save(cfg Config) error {
+	return SaveFile(configPath, cfg)
+}
+
+// SaveFile writes cfg to path, stamped with CurrentVersion.
+func SaveFile(path string, cfg Config) error {
+	cfg.Version = CurrentVersion
 	data, err := json.MarshalIndent(cfg, "", "  ")
 	if err != nil {
-		return
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return err
 	}
-	os.WriteFile(configPath, data, 0644)
+	slog.Info("config saved", "path", path)
+	return nil
 }
@@ -0,0 +1,224 @@
+package config
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		modify func(c *Config)
+		fields []string
+	}{
+		{
+			desc:   "default config is valid",
+			modify: func(c *Config) {},
+		},
+		{
+			desc:   "negative radius is rejected",
+			modify: func(c *Config) { c.AvoidanceRadius = -5 },
+			fields: []string{"avoidance_radius"},
+		},
+		{
+			desc:   "avoidance radius larger than detection radius is rejected",
+			modify: func(c *Config) { c.AvoidanceRadius = 150 },
+			fields: []string{"avoidance_radius"},
+		},
+		{
+			desc:   "zero max speed and wall margin are rejected",
+			modify: func(c *Config) { c.MaxSpeed = 0; c.WallMargin = 0 },
+			fields: []string{"max_speed", "wall_margin"},
+		},
+		{
+			desc:   "unknown color scheme is rejected",
+			modify: func(c *Config) { c.ColorScheme = "rainbow" },
+			fields: []string{"color_scheme"},
+		},
+		{
+			desc:   "NaN factor is rejected",
+			modify: func(c *Config) { c.AlignmentFactor = math.NaN() },
+			fields: []string{"alignment_factor"},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			cfg := Default()
+			tC.modify(&cfg)
+			err := cfg.Validate()
+			if len(tC.fields) == 0 {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+			var verrs ValidationErrors
+			if !errors.As(err, &verrs) {
+				t.Fatalf("expected ValidationErrors, got %v", err)
+			}
+			if len(verrs) != len(tC.fields) {
+				t.Errorf("expected %d errors, got %v", len(tC.fields), verrs)
+			}
+			for _, f := range tC.fields {
+				if !verrs.Has(f) {
+					t.Errorf("expected an error for %s, got %v", f, verrs)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		contents string
+		want     Config
+		fields   []string
+		parseErr bool
+	}{
+		{
+			desc:     "partial file is merged over defaults",
+			contents: `{"max_speed": 2}`,
+			want:     func() Config { c := Default(); c.MaxSpeed = 2; return c }(),
+		},
+		{
+			desc:     "unknown keys are reported",
+			contents: `{"max_sped": 2, "avoidance_radius": -1}`,
+			want:     func() Config { c := Default(); c.AvoidanceRadius = -1; return c }(),
+			fields:   []string{"max_sped", "avoidance_radius"},
+		},
+		{
+			desc:     "malformed json falls back to defaults",
+			contents: `{"max_speed": }`,
+			want:     Default(),
+			parseErr: true,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.json")
+			if err := os.WriteFile(path, []byte(tC.contents), 0644); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := LoadFile(path)
+			if cfg != tC.want {
+				t.Errorf("expected %+v, got %+v", tC.want, cfg)
+			}
+			var verrs ValidationErrors
+			switch {
+			case tC.parseErr:
+				if err == nil || errors.As(err, &verrs) {
+					t.Errorf("expected a parse error, got %v", err)
+				}
+			case len(tC.fields) == 0:
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+			default:
+				if !errors.As(err, &verrs) {
+					t.Fatalf("expected ValidationErrors, got %v", err)
+				}
+				for _, f := range tC.fields {
+					if !verrs.Has(f) {
+						t.Errorf("expected an error for %s, got %v", f, verrs)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestLoadFileMissing(t *testing.T) {
+	cfg, err := LoadFile(filepath.Join(t.TempDir(), "nope.json"))
+	if err != nil {
+		t.Errorf("expected no error for a missing file, got %v", err)
+	}
+	if cfg != Default() {
+		t.Errorf("expected defaults, got %+v", cfg)
+	}
+}
+
+func TestGetSet(t *testing.T) {
+	cfg := Default()
+	if err := cfg.Set("max_speed", 4.5); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.MaxSpeed != 4.5 {
+		t.Errorf("expected MaxSpeed 4.5, got %v", cfg.MaxSpeed)
+	}
+	if v, err := cfg.Get("wall_force"); err != nil || v != cfg.WallForce {
+		t.Errorf("expected wall_force %v, got %v, %v", cfg.WallForce, v, err)
+	}
+	if err := cfg.Set("version", 2); err == nil {
+		t.Error("expected version to be rejected")
+	}
+	if err := cfg.Set("max_sped", 2); err == nil {
+		t.Error("expected unknown key to be rejected")
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	testCases := []struct {
+		desc string
+		edit func(c *Config)
+		want func(c *Config)
+	}{
+		{
+			desc: "valid config is unchanged",
+			edit: func(c *Config) { c.MaxSpeed = 5 },
+			want: func(c *Config) { c.MaxSpeed = 5 },
+		},
+		{
+			desc: "invalid fields reset to defaults",
+			edit: func(c *Config) {
+				c.MaxSpeed, c.WallForce, c.AlignmentFactor, c.ColorScheme = -1, math.NaN(), 0.01, "rainbow"
+			},
+			want: func(c *Config) { c.AlignmentFactor = 0.01 },
+		},
+		{
+			desc: "cross-field error resets the field",
+			edit: func(c *Config) { c.AvoidanceRadius, c.DetectionRadius = 60, 50 },
+			want: func(c *Config) { c.DetectionRadius = 50 },
+		},
+		{
+			desc: "unfixable config falls back to defaults",
+			edit: func(c *Config) { c.AvoidanceRadius, c.DetectionRadius = 60, 10 },
+			want: func(c *Config) {},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			cfg, want := Default(), Default()
+			tC.edit(&cfg)
+			tC.want(&want)
+			if got := cfg.Sanitize(); got != want {
+				t.Errorf("expected %+v, got %+v", want, got)
+			}
+		})
+	}
+}
+
+func TestSaveFile(t *testing.T) {
+	dir := t.TempDir()
+	cfg := Default()
+	cfg.Version = 0
+	cfg.MaxSpeed = 4
+	path := filepath.Join(dir, "config.json")
+	if err := SaveFile(path, cfg); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Version = CurrentVersion
+	if got != cfg {
+		t.Errorf("expected %+v, got %+v", cfg, got)
+	}
+	if err := SaveFile(filepath.Join(dir, "missing", "config.json"), cfg); err == nil {
+		t.Error("expected an error writing into a missing directory")
+	}
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+)
+
+// CurrentVersion is the schema version written by Save.
+const CurrentVersion = 2
+
+// migrations[i] upgrades a config document from version i to i+1.
+var migrations = []func(doc map[string]json.RawMessage) error{
+	migrateV0,
+	migrateV1,
+}
+
+// Migrate upgrades a config document to CurrentVersion. Files without a
+// version field are treated as version 0. Documents from a newer version
+// are returned unchanged so Validate can report them.
+func Migrate(data []byte) ([]byte, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	version := 0
+	if v, ok := doc["version"]; ok {
+		if err := json.Unmarshal(v, &version); err != nil {
+			return nil, fmt.Errorf("version: %w", err)
+		}
+	}
+	if version < 0 || version >= CurrentVersion {
+		return data, nil
+	}
+	from := version
+	for ; version < CurrentVersion; version++ {
+		if err := migrations[version](doc); err != nil {
+			return nil, fmt.Errorf("migrate v%d to v%d: %w", version, version+1, err)
+		}
+		doc["version"] = json.RawMessage(fmt.Sprint(version + 1))
+	}
+	slog.Info("config migrated", "from", from, "to", CurrentVersion)
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// migrateV0 upgrades unversioned files. Early files only carried the
+// flocking factors and radii, so the speed and wall settings are filled in
+// explicitly to keep the upgraded file self-describing.
+func migrateV0(doc map[string]json.RawMessage) error {
+	legacy := map[string]float64{
+		"max_speed":   3,
+		"wall_margin": 75,
+		"wall_force":  1.5,
+	}
+	for key, val := range legacy {
+		if _, ok := doc[key]; !ok {
+			doc[key] = json.RawMessage(fmt.Sprint(val))
+		}
+	}
+	return nil
+}
+
+// migrateV1 adds color_scheme, which version 2 introduced, with the plain
+// white lings every earlier version drew.
+func migrateV1(doc map[string]json.RawMessage) error {
+	if _, ok := doc["color_scheme"]; !ok {
+		doc["color_scheme"] = json.RawMessage(`"plain"`)
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+)
+
+const presetsPath = "presets.json"
+
+type Preset struct {
+	Name   string `json:"name"`
+	Config Config `json:"config"`
+}
+
+type Presets []Preset
+
+// BuiltinPresets are used when no presets file exists yet.
+func BuiltinPresets() Presets {
+	tight := Default()
+	tight.AvoidanceRadius = 12
+	tight.AlignmentFactor = 0.008
+	tight.GatheringFactor = 0.0015
+	tight.DetectionRadius = 80
+
+	torus := Default()
+	torus.AvoidanceFactor = 1.2
+	torus.AlignmentFactor = 0.0015
+	torus.GatheringFactor = 0.0018
+	torus.DetectionRadius = 160
+	torus.MaxSpeed = 2.5
+
+	loose := Default()
+	loose.AvoidanceFactor = 1.6
+	loose.AvoidanceRadius = 35
+	loose.AlignmentFactor = 0.001
+	loose.GatheringFactor = 0.0002
+	loose.DetectionRadius = 120
+
+	return Presets{
+		{Name: "tight flock", Config: tight},
+		{Name: "milling torus", Config: torus},
+		{Name: "loose swarm", Config: loose},
+	}
+}
+
+func (p Presets) Get(name string) (Config, bool) {
+	for _, preset := range p {
+		if preset.Name == name {
+			return preset.Config, true
+		}
+	}
+	return Config{}, false
+}
+
+// Set returns a copy of p with the preset called name replaced, or
+// appended if there is none. p itself is left alone, so a failed save
+// doesn't corrupt the list in memory.
+func (p Presets) Set(name string, cfg Config) Presets {
+	cfg.Version = CurrentVersion
+	next := slices.Clone(p)
+	for i := range next {
+		if next[i].Name == name {
+			next[i].Config = cfg
+			return next
+		}
+	}
+	return append(next, Preset{Name: name, Config: cfg})
+}
+
+// Delete returns a copy of p without the preset called name.
+func (p Presets) Delete(name string) Presets {
+	return slices.DeleteFunc(slices.Clone(p), func(preset Preset) bool {
+		return preset.Name == name
+	})
+}
+
+type presetsFile struct {
+	Presets []json.RawMessage `json:"presets"`
+}
+
+func LoadPresets() (Presets, error) {
+	return LoadPresetsFile(presetsPath)
+}
+
+// LoadPresetsFile reads a presets file. A missing file yields the built-in
+// presets. Each preset's config is migrated and validated on its own; broken
+// presets are skipped and reported while the rest are still returned.
+func LoadPresetsFile(path string) (Presets, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return BuiltinPresets(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var file presetsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	var presets Presets
+	var errs ValidationErrors
+	for i, raw := range file.Presets {
+		var entry struct {
+			Name   string          `json:"name"`
+			Config json.RawMessage `json:"config"`
+		}
+		if err := json.Unmarshal(raw, &entry); err != nil {
+			errs = append(errs, FieldError{fmt.Sprintf("presets[%d]", i), err.Error()})
+			continue
+		}
+		name := strings.TrimSpace(entry.Name)
+		if name == "" {
+			errs = append(errs, FieldError{fmt.Sprintf("presets[%d]", i), "missing name"})
+			continue
+		}
+		cfg, err := decodeConfig(entry.Config)
+		if err != nil {
+			errs = append(errs, FieldError{fmt.Sprintf("presets[%q]", name), err.Error()})
+			continue
+		}
+		presets = presets.Set(name, cfg)
+	}
+	if len(errs) > 0 {
+		return presets, errs
+	}
+	return presets, nil
+}
+
+func SavePresets(presets Presets) error {
+	return SavePresetsFile(presetsPath, presets)
+}
+
+func SavePresetsFile(path string, presets Presets) error {
+	// Stamp a copy; the caller's presets are left alone.
+	presets = slices.Clone(presets)
+	if presets == nil {
+		presets = Presets{}
+	}
+	for i := range presets {
+		presets[i].Config.Version = CurrentVersion
+	}
+	data, err := json.MarshalIndent(struct {
+		Presets Presets `json:"presets"`
+	}{presets}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return err
+	}
+	slog.Info("presets saved", "path", path, "count", len(presets))
+	return nil
+}
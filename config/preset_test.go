@@ -0,0 +1,107 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPresetsSetAndDelete(t *testing.T) {
+	presets := BuiltinPresets()
+	n := len(presets)
+
+	cfg := Default()
+	cfg.MaxSpeed = 7
+	presets = presets.Set("fast", cfg)
+	if len(presets) != n+1 {
+		t.Fatalf("expected %d presets, got %d", n+1, len(presets))
+	}
+	cfg.MaxSpeed = 8
+	presets = presets.Set("fast", cfg)
+	if len(presets) != n+1 {
+		t.Errorf("expected Set to replace an existing preset, got %d presets", len(presets))
+	}
+	if got, ok := presets.Get("fast"); !ok || got.MaxSpeed != 8 {
+		t.Errorf("expected fast preset with max speed 8, got %+v (found=%v)", got, ok)
+	}
+
+	before := presets
+	presets = presets.Delete("fast")
+	if _, ok := presets.Get("fast"); ok || len(presets) != n {
+		t.Errorf("expected fast preset to be deleted, got %+v", presets)
+	}
+	if got, ok := before.Get("fast"); !ok || got.MaxSpeed != 8 || len(before) != n+1 {
+		t.Errorf("expected Delete to leave the original list alone, got %+v", before)
+	}
+	cfg.MaxSpeed = 9
+	before.Set("fast", cfg)
+	if got, _ := before.Get("fast"); got.MaxSpeed != 8 {
+		t.Errorf("expected Set to leave the original list alone, got max speed %v", got.MaxSpeed)
+	}
+}
+
+func TestBuiltinPresetsAreValid(t *testing.T) {
+	for _, p := range BuiltinPresets() {
+		if err := p.Config.Validate(); err != nil {
+			t.Errorf("preset %q: %v", p.Name, err)
+		}
+	}
+}
+
+func TestPresetsRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "presets.json")
+
+	presets, err := LoadPresetsFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(presets) != len(BuiltinPresets()) {
+		t.Errorf("expected built-in presets for a missing file, got %+v", presets)
+	}
+
+	presets = presets.Set("custom", Default())
+	presets[0].Config.Version = 0
+	if err := SavePresetsFile(path, presets); err != nil {
+		t.Fatal(err)
+	}
+	if presets[0].Config.Version != 0 {
+		t.Error("expected saving to leave the caller's presets alone")
+	}
+	presets[0].Config.Version = CurrentVersion
+	loaded, err := LoadPresetsFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != len(presets) {
+		t.Fatalf("expected %d presets, got %d", len(presets), len(loaded))
+	}
+	for i := range presets {
+		if loaded[i] != presets[i] {
+			t.Errorf("preset %d: expected %+v, got %+v", i, presets[i], loaded[i])
+		}
+	}
+}
+
+func TestLoadPresetsSkipsInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "presets.json")
+	contents := `{"presets": [
+		{"name": "ok", "config": {"version": 1, "max_speed": 2}},
+		{"name": "bad", "config": {"version": 1, "max_speed": -2}},
+		{"name": "old", "config": {"avoidance_radius": 10}}
+	]}`
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	presets, err := LoadPresetsFile(path)
+	var verrs ValidationErrors
+	if !errors.As(err, &verrs) || len(verrs) != 1 {
+		t.Fatalf("expected one validation error, got %v", err)
+	}
+	if _, ok := presets.Get("bad"); ok {
+		t.Error("expected invalid preset to be skipped")
+	}
+	if old, ok := presets.Get("old"); !ok || old.Version != CurrentVersion || old.AvoidanceRadius != 10 {
+		t.Errorf("expected unversioned preset to be migrated, got %+v", old)
+	}
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"time"
+)
+
+// FieldChange records a single field that differs between two configs.
+type FieldChange struct {
+	Field    string
+	Old, New any
+}
+
+func (c FieldChange) String() string {
+	return fmt.Sprintf("%s %v -> %v", c.Field, c.Old, c.New)
+}
+
+// Diff lists the fields whose values differ from a to b, by JSON key.
+func Diff(a, b Config) []FieldChange {
+	var changes []FieldChange
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	for i, name := range jsonKeys {
+		if !va.Field(i).Equal(vb.Field(i)) {
+			changes = append(changes, FieldChange{name, va.Field(i).Interface(), vb.Field(i).Interface()})
+		}
+	}
+	return changes
+}
+
+// ApplyChanges sets each changed field in dst to its new value, leaving
+// every other field alone.
+func ApplyChanges(dst *Config, changes []FieldChange) {
+	v := reflect.ValueOf(dst).Elem()
+	for _, c := range changes {
+		if i := slices.Index(jsonKeys, c.Field); i >= 0 {
+			v.Field(i).Set(reflect.ValueOf(c.New))
+		}
+	}
+}
+
+// Watcher polls a config file's modification time and reloads it when it
+// changes.
+type Watcher struct {
+	path    string
+	modTime time.Time
+	size    int64
+	loaded  Config
+}
+
+func Watch(loaded Config) *Watcher {
+	return NewWatcher(configPath, loaded)
+}
+
+// NewWatcher starts watching path. loaded is the config that was read from
+// it, used as the baseline for the first diff.
+func NewWatcher(path string, loaded Config) *Watcher {
+	w := &Watcher{path: path, loaded: loaded}
+	if info, err := os.Stat(path); err == nil {
+		w.modTime = info.ModTime()
+		w.size = info.Size()
+	}
+	return w
+}
+
+func (w *Watcher) Path() string {
+	return w.path
+}
+
+// Poll reloads the file if it changed since the last call and returns the
+// fields that differ from the previous successful load. A file that fails
+// to parse or validate is reported once and otherwise ignored, so the next
+// good edit is diffed against the last good one.
+func (w *Watcher) Poll() ([]FieldChange, error) {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return nil, nil
+	}
+	if info.ModTime().Equal(w.modTime) && info.Size() == w.size {
+		return nil, nil
+	}
+	w.modTime = info.ModTime()
+	w.size = info.Size()
+
+	cfg, err := LoadFile(w.path)
+	if err != nil {
+		if verr := cfg.Validate(); verr != nil || !onlyUnknownKeys(err) {
+			return nil, err
+		}
+	}
+	changes := Diff(w.loaded, cfg)
+	w.loaded = cfg
+	return changes, err
+}
+
+func onlyUnknownKeys(err error) bool {
+	verrs, ok := err.(ValidationErrors)
+	if !ok {
+		return false
+	}
+	for _, fe := range verrs {
+		if fe.Message != msgUnknownKey {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, path, contents string, mtime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWatcherPoll(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	start := time.Now().Add(-time.Hour)
+	writeConfig(t, path, `{"version": 1, "max_speed": 2}`, start)
+	loaded, err := LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := NewWatcher(path, loaded)
+
+	if changes, err := w.Poll(); err != nil || len(changes) != 0 {
+		t.Fatalf("expected no changes for an untouched file, got %v, %v", changes, err)
+	}
+
+	writeConfig(t, path, `{"version": 1, "max_speed": 4, "wall_force": 2}`, start.Add(time.Minute))
+	changes, err := w.Poll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 || changes[0].Field != "max_speed" || changes[1].Field != "wall_force" {
+		t.Fatalf("expected max_speed and wall_force to change, got %v", changes)
+	}
+
+	running := Default()
+	running.AlignmentFactor = 0.009
+	ApplyChanges(&running, changes)
+	if running.MaxSpeed != 4 || running.WallForce != 2 || running.AlignmentFactor != 0.009 {
+		t.Errorf("expected only the changed fields to be applied, got %+v", running)
+	}
+
+	writeConfig(t, path, `{"version": 1, "max_speed": -1, "wall_force": 2}`, start.Add(2*time.Minute))
+	if changes, err := w.Poll(); err == nil || len(changes) != 0 {
+		t.Errorf("expected invalid edit to be rejected, got %v, %v", changes, err)
+	}
+
+	writeConfig(t, path, `{"version": 1, "max_speed": 4, "wall_force": 3}`, start.Add(3*time.Minute))
+	changes, err = w.Poll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Field != "wall_force" {
+		t.Errorf("expected diff against the last good load, got %v", changes)
+	}
+}
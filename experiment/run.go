@@ -0,0 +1,94 @@
+package experiment
+
+import (
+	"runtime"
+	"swarmlings/config"
+	"swarmlings/sim"
+	"sync"
+)
+
+// WorldOptions describes the headless world each run starts from.
+type WorldOptions struct {
+	Population int `json:"population"`
+	Width      int `json:"width"`
+	Height     int `json:"height"`
+}
+
+func DefaultWorldOptions() WorldOptions {
+	return WorldOptions{Population: 1000, Width: 800, Height: 600}
+}
+
+// Result is the state of a run after its last tick.
+type Result struct {
+	Metrics sim.Metrics
+	Flocks  int
+}
+
+// MetricNames lists the names accepted by Result.Metric, in CSV column order.
+var MetricNames = []string{"population", "polarization", "milling", "mean_nn_distance", "avg_speed", "density", "flocks"}
+
+// Metric looks up one of the final metrics by name.
+func (r Result) Metric(name string) (float64, bool) {
+	m := r.Metrics
+	switch name {
+	case "population":
+		return float64(m.Population), true
+	case "polarization":
+		return m.Polarization, true
+	case "milling":
+		return m.Milling, true
+	case "mean_nn_distance":
+		return m.MeanNearestNeighbor, true
+	case "avg_speed":
+		return m.AverageSpeed, true
+	case "density":
+		return m.Density, true
+	case "flocks":
+		return float64(r.Flocks), true
+	}
+	return 0, false
+}
+
+// NewWorld builds a world with cfg applied and lings placed uniformly at
+// random from seed, the same way the windowed app starts.
+func NewWorld(cfg config.Config, opts WorldOptions, seed int64) sim.World {
+	lings := sim.Spawner{Population: opts.Population}.Spawn(opts.Width, opts.Height, seed)
+	world := sim.New(lings, opts.Width, opts.Height)
+	cfg.Apply(&world)
+	return world
+}
+
+// Run simulates ticks steps without rendering and reports the final state.
+func Run(cfg config.Config, opts WorldOptions, seed int64, ticks int) Result {
+	world := NewWorld(cfg, opts, seed)
+	for range ticks {
+		world.Update()
+	}
+	flocks := sim.NewFlockTracker(0)
+	flocks.Update(&world)
+	return Result{Metrics: world.Metrics(), Flocks: flocks.Count()}
+}
+
+// parallel calls fn for every index in [0, n) on up to workers goroutines;
+// workers <= 0 means one per CPU.
+func parallel(n, workers int, fn func(i int)) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+	for i := range n {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
@@ -0,0 +1,205 @@
+package experiment
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"swarmlings/config"
+)
+
+// Range lists the values one parameter takes in a sweep: either explicit
+// Values, or Steps evenly spaced values from Min to Max inclusive.
+type Range struct {
+	Values []float64 `json:"values,omitempty"`
+	Min    float64   `json:"min,omitempty"`
+	Max    float64   `json:"max,omitempty"`
+	Steps  int       `json:"steps,omitempty"`
+}
+
+func (r Range) expand() ([]float64, error) {
+	if len(r.Values) > 0 {
+		return r.Values, nil
+	}
+	switch {
+	case r.Steps == 1:
+		return []float64{r.Min}, nil
+	case r.Steps < 1:
+		return nil, errors.New("needs values or steps >= 1")
+	}
+	vals := make([]float64, r.Steps)
+	for i := range vals {
+		vals[i] = r.Min + (r.Max-r.Min)*float64(i)/float64(r.Steps-1)
+	}
+	return vals, nil
+}
+
+// Sweep is a grid of parameter combinations, each run Replicates times
+// with different seeds.
+type Sweep struct {
+	// Base supplies every parameter that isn't swept.
+	Base       config.Config    `json:"base"`
+	Params     map[string]Range `json:"params"`
+	Replicates int              `json:"replicates"`
+	Ticks      int              `json:"ticks"`
+	Seed       int64            `json:"seed"`
+	World      WorldOptions     `json:"world"`
+	// Workers is the number of worlds simulated in parallel; 0 means one
+	// per CPU.
+	Workers int `json:"workers,omitempty"`
+}
+
+// LoadSweep reads a sweep spec. Fields the spec leaves out fall back to
+// base, the world defaults, one replicate and 1000 ticks.
+func LoadSweep(path string, base config.Config) (Sweep, error) {
+	sw := Sweep{Base: base, Replicates: 1, Ticks: 1000, Seed: 1, World: DefaultWorldOptions()}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return sw, err
+	}
+	if err := json.Unmarshal(data, &sw); err != nil {
+		return sw, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return sw, nil
+}
+
+// Point is one parameter combination of a sweep.
+type Point struct {
+	Index  int
+	Config config.Config
+	Values map[string]float64
+}
+
+// params returns the swept parameter names in a stable order.
+func (s Sweep) params() []string {
+	names := make([]string, 0, len(s.Params))
+	for name := range s.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Points expands the sweep into every combination of parameter values and
+// checks each resulting config.
+func (s Sweep) Points() ([]Point, error) {
+	names := s.params()
+	values := make([][]float64, len(names))
+	for i, name := range names {
+		if _, err := s.Base.Get(name); err != nil {
+			return nil, err
+		}
+		vals, err := s.Params[name].expand()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		values[i] = vals
+	}
+
+	var points []Point
+	idx := make([]int, len(names))
+	for {
+		p := Point{Index: len(points), Config: s.Base, Values: make(map[string]float64)}
+		for i, name := range names {
+			v := values[i][idx[i]]
+			p.Config.Set(name, v)
+			p.Values[name] = v
+		}
+		if err := p.Config.Validate(); err != nil {
+			return nil, fmt.Errorf("point %v: %w", p.Values, err)
+		}
+		points = append(points, p)
+
+		// Advance the odometer, last parameter fastest.
+		i := len(idx) - 1
+		for ; i >= 0; i-- {
+			idx[i]++
+			if idx[i] < len(values[i]) {
+				break
+			}
+			idx[i] = 0
+		}
+		if i < 0 {
+			return points, nil
+		}
+	}
+}
+
+func (s Sweep) validate() error {
+	switch {
+	case s.Ticks <= 0:
+		return errors.New("ticks must be greater than 0")
+	case s.Replicates <= 0:
+		return errors.New("replicates must be greater than 0")
+	case s.World.Population <= 0:
+		return errors.New("world.population must be greater than 0")
+	case s.World.Width <= 0 || s.World.Height <= 0:
+		return errors.New("world.width and world.height must be greater than 0")
+	}
+	return nil
+}
+
+// Row is the outcome of one replicate at one point.
+type Row struct {
+	Point     Point
+	Replicate int
+	Seed      int64
+	Result    Result
+}
+
+// Run simulates every point and replicate in parallel. Rows come back in
+// point then replicate order regardless of scheduling. Replicate r of every
+// point starts from seed Seed+r, so points are compared on identical initial
+// conditions and results are reproducible.
+func (s Sweep) Run() ([]Row, error) {
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	points, err := s.Points()
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]Row, len(points)*s.Replicates)
+	for i := range rows {
+		p := points[i/s.Replicates]
+		rep := i % s.Replicates
+		rows[i] = Row{Point: p, Replicate: rep, Seed: s.Seed + int64(rep)}
+	}
+
+	parallel(len(rows), s.Workers, func(i int) {
+		rows[i].Result = Run(rows[i].Point.Config, s.World, rows[i].Seed, s.Ticks)
+	})
+	return rows, nil
+}
+
+// WriteCSV writes one line per row: the swept parameters followed by the
+// final metrics.
+func (s Sweep) WriteCSV(w io.Writer, rows []Row) error {
+	names := s.params()
+	cw := csv.NewWriter(w)
+	header := append([]string{"point", "replicate", "seed"}, names...)
+	header = append(header, MetricNames...)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	f := func(v float64) string { return strconv.FormatFloat(v, 'g', 6, 64) }
+	for _, r := range rows {
+		rec := []string{strconv.Itoa(r.Point.Index), strconv.Itoa(r.Replicate), strconv.FormatInt(r.Seed, 10)}
+		for _, name := range names {
+			rec = append(rec, f(r.Point.Values[name]))
+		}
+		for _, name := range MetricNames {
+			v, _ := r.Result.Metric(name)
+			rec = append(rec, f(v))
+		}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,103 @@
+package experiment
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"strings"
+	"swarmlings/config"
+	"testing"
+)
+
+func TestSweepPoints(t *testing.T) {
+	sw := Sweep{
+		Base: config.Default(),
+		Params: map[string]Range{
+			"avoidance_factor": {Min: 0.5, Max: 1.5, Steps: 3},
+			"alignment_factor": {Values: []float64{0.001, 0.004}},
+		},
+	}
+	points, err := sw.Points()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(points) != 6 {
+		t.Fatalf("expected 6 points, got %d", len(points))
+	}
+	// alignment_factor sorts first, so avoidance_factor varies fastest.
+	want := [][2]float64{{0.001, 0.5}, {0.001, 1}, {0.001, 1.5}, {0.004, 0.5}, {0.004, 1}, {0.004, 1.5}}
+	for i, p := range points {
+		if p.Config.AlignmentFactor != want[i][0] || p.Config.AvoidanceFactor != want[i][1] {
+			t.Errorf("point %d: expected align=%v avoid=%v, got %+v", i, want[i][0], want[i][1], p.Values)
+		}
+		if p.Config.GatheringFactor != sw.Base.GatheringFactor {
+			t.Errorf("point %d: expected unswept fields from base", i)
+		}
+	}
+}
+
+func TestSweepRejectsBadSpecs(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		params map[string]Range
+	}{
+		{
+			desc:   "unknown parameter",
+			params: map[string]Range{"max_sped": {Values: []float64{1}}},
+		},
+		{
+			desc:   "range without steps",
+			params: map[string]Range{"max_speed": {Min: 1, Max: 2}},
+		},
+		{
+			desc:   "value that fails validation",
+			params: map[string]Range{"max_speed": {Values: []float64{1, -1}}},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			sw := Sweep{Base: config.Default(), Params: tC.params}
+			if _, err := sw.Points(); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestSweepRunIsReproducible(t *testing.T) {
+	sw := Sweep{
+		Base:       config.Default(),
+		Params:     map[string]Range{"gathering_factor": {Values: []float64{0.0002, 0.001}}},
+		Replicates: 2,
+		Ticks:      20,
+		Seed:       5,
+		World:      WorldOptions{Population: 60, Width: 300, Height: 200},
+	}
+	run := func(workers int) []Row {
+		sw.Workers = workers
+		rows, err := sw.Run()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rows
+	}
+	serial, parallel := run(1), run(4)
+	if len(serial) != 4 {
+		t.Fatalf("expected 4 rows, got %d", len(serial))
+	}
+	if !reflect.DeepEqual(serial, parallel) {
+		t.Error("expected identical results regardless of worker count")
+	}
+
+	var buf bytes.Buffer
+	if err := sw.WriteCSV(&buf, serial); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(strings.NewReader(buf.String())).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 5 || records[0][3] != "gathering_factor" || records[1][4] != "60" {
+		t.Errorf("unexpected csv:\n%s", buf.String())
+	}
+}
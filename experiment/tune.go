@@ -0,0 +1,247 @@
+package experiment
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"swarmlings/config"
+)
+
+// Target is a bound on one final metric. Either bound may be omitted.
+type Target struct {
+	Metric string   `json:"metric"`
+	Min    *float64 `json:"min,omitempty"`
+	Max    *float64 `json:"max,omitempty"`
+}
+
+// violation is how far v falls outside the target, relative to the bound
+// so that metrics on different scales weigh roughly the same.
+func (t Target) violation(v float64) float64 {
+	rel := func(diff, bound float64) float64 {
+		return diff / math.Max(math.Abs(bound), 1e-9)
+	}
+	if t.Min != nil && v < *t.Min {
+		return rel(*t.Min-v, *t.Min)
+	}
+	if t.Max != nil && v > *t.Max {
+		return rel(v-*t.Max, *t.Max)
+	}
+	return 0
+}
+
+// Bounds is the search interval for one parameter.
+type Bounds struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// Tuning searches the parameter space with a small genetic algorithm for a
+// config whose final metrics satisfy every target.
+type Tuning struct {
+	Base        config.Config     `json:"base"`
+	Params      map[string]Bounds `json:"params"`
+	Targets     []Target          `json:"targets"`
+	Generations int               `json:"generations"`
+	// PopulationSize is the number of candidate configs per generation.
+	PopulationSize int          `json:"population_size"`
+	Replicates     int          `json:"replicates"`
+	Ticks          int          `json:"ticks"`
+	Seed           int64        `json:"seed"`
+	World          WorldOptions `json:"world"`
+	Workers        int          `json:"workers,omitempty"`
+}
+
+// LoadTuning reads a tuning spec, with the same fallbacks as LoadSweep plus
+// 20 generations of 16 candidates.
+func LoadTuning(path string, base config.Config) (Tuning, error) {
+	tn := Tuning{
+		Base:           base,
+		Generations:    20,
+		PopulationSize: 16,
+		Replicates:     1,
+		Ticks:          1000,
+		Seed:           1,
+		World:          DefaultWorldOptions(),
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return tn, err
+	}
+	if err := json.Unmarshal(data, &tn); err != nil {
+		return tn, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return tn, nil
+}
+
+func (tn Tuning) validate() error {
+	switch {
+	case len(tn.Params) == 0:
+		return errors.New("no params to tune")
+	case len(tn.Targets) == 0:
+		return errors.New("no targets")
+	case tn.Generations <= 0:
+		return errors.New("generations must be greater than 0")
+	case tn.PopulationSize < 2:
+		return errors.New("population_size must be at least 2")
+	case tn.Replicates <= 0:
+		return errors.New("replicates must be greater than 0")
+	case tn.Ticks <= 0:
+		return errors.New("ticks must be greater than 0")
+	case tn.World.Population <= 0 || tn.World.Width <= 0 || tn.World.Height <= 0:
+		return errors.New("world population and size must be greater than 0")
+	}
+	for name, b := range tn.Params {
+		if _, err := tn.Base.Get(name); err != nil {
+			return err
+		}
+		if b.Max < b.Min {
+			return fmt.Errorf("%s: max is below min", name)
+		}
+	}
+	for _, t := range tn.Targets {
+		if _, ok := (Result{}).Metric(t.Metric); !ok {
+			return fmt.Errorf("unknown metric %q", t.Metric)
+		}
+		if t.Min == nil && t.Max == nil {
+			return fmt.Errorf("target %s needs min or max", t.Metric)
+		}
+	}
+	return nil
+}
+
+// Candidate is one evaluated config. Score is the summed target violation
+// averaged over replicates; 0 means every target is met.
+type Candidate struct {
+	Config  config.Config
+	Score   float64
+	Results []Result
+}
+
+// Tune runs the search and returns the best candidate found. progress, if
+// not nil, is called after each generation with the best so far. The search
+// stops early once a candidate meets every target.
+func (tn Tuning) Tune(progress func(gen int, best Candidate)) (Candidate, error) {
+	if err := tn.validate(); err != nil {
+		return Candidate{}, err
+	}
+	names := make([]string, 0, len(tn.Params))
+	for name := range tn.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	rng := rand.New(rand.NewSource(tn.Seed))
+
+	clamp := func(cfg *config.Config) {
+		for _, name := range names {
+			b := tn.Params[name]
+			v, _ := cfg.Get(name)
+			cfg.Set(name, math.Max(b.Min, math.Min(b.Max, v)))
+		}
+	}
+	random := func() config.Config {
+		cfg := tn.Base
+		for _, name := range names {
+			b := tn.Params[name]
+			cfg.Set(name, b.Min+rng.Float64()*(b.Max-b.Min))
+		}
+		return cfg
+	}
+
+	// Start from the base config plus random samples.
+	pop := make([]Candidate, tn.PopulationSize)
+	pop[0].Config = tn.Base
+	clamp(&pop[0].Config)
+	for i := 1; i < len(pop); i++ {
+		pop[i].Config = random()
+	}
+
+	var best Candidate
+	for gen := range tn.Generations {
+		tn.evaluate(pop)
+		sort.SliceStable(pop, func(a, b int) bool { return pop[a].Score < pop[b].Score })
+		if gen == 0 || pop[0].Score < best.Score {
+			best = pop[0]
+		}
+		if progress != nil {
+			progress(gen, best)
+		}
+		if best.Score == 0 || gen == tn.Generations-1 {
+			break
+		}
+
+		tournament := func() config.Config {
+			pick := pop[rng.Intn(len(pop))]
+			for range 2 {
+				if c := pop[rng.Intn(len(pop))]; c.Score < pick.Score {
+					pick = c
+				}
+			}
+			return pick.Config
+		}
+		// Keep the two best unchanged, breed the rest.
+		next := make([]Candidate, len(pop))
+		elite := min(2, len(pop))
+		copy(next, pop[:elite])
+		for i := elite; i < len(next); i++ {
+			a, b := tournament(), tournament()
+			child := a
+			for _, name := range names {
+				v, _ := a.Get(name)
+				if rng.Intn(2) == 0 {
+					v, _ = b.Get(name)
+				}
+				if rng.Float64() < 0.25 {
+					bd := tn.Params[name]
+					v += rng.NormFloat64() * 0.1 * (bd.Max - bd.Min)
+				}
+				child.Set(name, v)
+			}
+			clamp(&child)
+			next[i].Config = child
+		}
+		pop = next
+	}
+	return best, nil
+}
+
+// evaluate scores every candidate that hasn't been scored yet. Replicate r
+// always uses seed Seed+r, so candidates are compared on the same starts.
+func (tn Tuning) evaluate(pop []Candidate) {
+	type job struct{ cand, rep int }
+	var jobs []job
+	for i := range pop {
+		if pop[i].Results != nil {
+			continue
+		}
+		if pop[i].Config.Validate() != nil {
+			pop[i].Score = math.Inf(1)
+			pop[i].Results = []Result{}
+			continue
+		}
+		pop[i].Results = make([]Result, tn.Replicates)
+		for r := range tn.Replicates {
+			jobs = append(jobs, job{i, r})
+		}
+	}
+	parallel(len(jobs), tn.Workers, func(j int) {
+		c, r := jobs[j].cand, jobs[j].rep
+		pop[c].Results[r] = Run(pop[c].Config, tn.World, tn.Seed+int64(r), tn.Ticks)
+	})
+	for _, jb := range jobs {
+		if jb.rep != 0 {
+			continue
+		}
+		var total float64
+		for _, res := range pop[jb.cand].Results {
+			for _, t := range tn.Targets {
+				v, _ := res.Metric(t.Metric)
+				total += t.violation(v)
+			}
+		}
+		pop[jb.cand].Score = total / float64(len(pop[jb.cand].Results))
+	}
+}
@@ -0,0 +1,70 @@
+package experiment
+
+import (
+	"swarmlings/config"
+	"testing"
+)
+
+func ptr(v float64) *float64 { return &v }
+
+func TestTargetViolation(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		target   Target
+		value    float64
+		expected float64
+	}{
+		{"inside bounds", Target{Min: ptr(0.5), Max: ptr(1)}, 0.7, 0},
+		{"below min", Target{Min: ptr(0.8)}, 0.4, 0.5},
+		{"above max", Target{Max: ptr(2)}, 3, 0.5},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			if got := tC.target.violation(tC.value); got != tC.expected {
+				t.Errorf("expected violation %v, got %v", tC.expected, got)
+			}
+		})
+	}
+}
+
+func TestTuneFindsTarget(t *testing.T) {
+	// Average speed is capped by max_speed, so the tuner has to push
+	// max_speed down into a narrow band to hit this target.
+	tn := Tuning{
+		Base:           config.Default(),
+		Params:         map[string]Bounds{"max_speed": {Min: 0.5, Max: 6}},
+		Targets:        []Target{{Metric: "avg_speed", Min: ptr(0.9), Max: ptr(1.1)}},
+		Generations:    15,
+		PopulationSize: 8,
+		Replicates:     1,
+		Ticks:          30,
+		Seed:           3,
+		World:          WorldOptions{Population: 40, Width: 300, Height: 300},
+	}
+	best, err := tn.Tune(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if best.Score != 0 {
+		t.Errorf("expected the target to be met, got score %v with max_speed %v", best.Score, best.Config.MaxSpeed)
+	}
+	if best.Config.MaxSpeed < 0.5 || best.Config.MaxSpeed > 6 {
+		t.Errorf("expected max_speed within bounds, got %v", best.Config.MaxSpeed)
+	}
+}
+
+func TestTuneRejectsBadSpecs(t *testing.T) {
+	tn := Tuning{
+		Base:           config.Default(),
+		Params:         map[string]Bounds{"max_speed": {Min: 1, Max: 2}},
+		Targets:        []Target{{Metric: "happiness", Min: ptr(1)}},
+		Generations:    1,
+		PopulationSize: 4,
+		Replicates:     1,
+		Ticks:          1,
+		World:          DefaultWorldOptions(),
+	}
+	if _, err := tn.Tune(nil); err == nil {
+		t.Error("expected unknown metric to be rejected")
+	}
+}
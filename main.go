@@ -1,47 +1,105 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
 	"image/color"
-	"log"
+	"log/slog"
 	"math/rand"
+	"os"
 	"swarmlings/config"
 	"swarmlings/render"
+	"swarmlings/server"
 	"swarmlings/sim"
+	"swarmlings/telemetry"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
 func main() {
-	lings := make([]sim.Ling, 1000)
-	world := sim.New(lings, 800, 600)
-	for i := range world.Lings {
-		world.Lings[i] = sim.Ling{
-			X:    rand.Float64() * float64(world.Width),
-			Y:    rand.Float64() * float64(world.Height),
-			VX:   rand.Float64() * 1,
-			VY:   rand.Float64() * 1,
-			Size: 5,
-		}
+	httpAddr := flag.String("http", "", "serve the control API on this address, e.g. localhost:8080")
+	metricsAddr := flag.String("metrics", "", "serve Prometheus metrics at /metrics on this address, e.g. localhost:9090")
+	logLevel := flag.String("log-level", "info", "minimum log level: debug, info, warn or error")
+	logJSON := flag.Bool("log-json", false, "write logs as JSON lines instead of text")
+	worldSize := flag.String("world", "800x600", "world size in world units, WIDTHxHEIGHT")
+	resizeFlag := flag.String("resize", "letterbox", "what window resizes do to the world: letterbox, stretch or expand")
+	trailLength := flag.Int("trail-length", 40, "ticks of history kept for motion trails (T)")
+	trailSample := flag.Int("trail-sample", 1, "draw the trail of every Nth ling")
+	flag.Parse()
+
+	if err := setupLogging(*logLevel, *logJSON); err != nil {
+		fatal("logging", err)
 	}
 
-	cfg := config.Load()
+	var width, height int
+	if _, err := fmt.Sscanf(*worldSize, "%dx%d", &width, &height); err != nil || width <= 0 || height <= 0 {
+		fatal("world size", fmt.Errorf("want WIDTHxHEIGHT, got %q", *worldSize))
+	}
+	resize, err := render.ParseResizeMode(*resizeFlag)
+	if err != nil {
+		fatal("resize mode", err)
+	}
+	world := sim.New(nil, width, height)
+	spawner := sim.Spawner{Population: 1000, Distribution: sim.SpawnUniform, Velocity: sim.VelocityRandom}
+	world.Lings = spawner.Spawn(width, height, rand.Int63())
 
-	world.AvoidanceFactor = cfg.AvoidanceFactor
-	world.AlignmentFactor = cfg.AlignmentFactor
-	world.GatheringFactor = cfg.GatheringFactor
-	world.AvoidanceRadius = cfg.AvoidanceRadius
-	world.DetectionRadius = cfg.DetectionRadius
-	world.MaxSpeed = cfg.MaxSpeed
-	world.WallMargin = cfg.WallMargin
-	world.WallForce = cfg.WallForce
+	cfg, err := config.Load()
+	var verrs config.ValidationErrors
+	if errors.As(err, &verrs) {
+		slog.Warn("config has invalid values, using defaults for them", "err", err)
+		cfg = cfg.Sanitize()
+	} else if err != nil {
+		slog.Warn("config unreadable, using defaults", "err", err)
+	}
 
-	ui := render.BuildUI(&world, &cfg, 1.0)
+	cfg.Apply(&world)
 
 	texture := ebiten.NewImage(1, 1)
 	texture.Fill(color.White)
+	ebiten.SetWindowSize(800, 600)
 	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
-	game := &render.Game{World: &world, Cfg: &cfg, Texture: texture, ShowUI: true, Ui: ui}
+	game := &render.Game{World: &world, Cfg: &cfg, LoadErr: err, Texture: texture, ShowUI: true, Watcher: config.Watch(cfg), Spawner: spawner, Resize: resize, TrailLength: *trailLength, TrailSample: *trailSample, Tools: render.DefaultTools()}
+
+	if *httpAddr != "" {
+		game.Remote = server.New(game)
+		if err := game.Remote.Start(*httpAddr); err != nil {
+			fatal("control API", err)
+		}
+		slog.Info("control API listening", "url", "http://"+game.Remote.Addr().String())
+	}
+
+	if *metricsAddr != "" {
+		game.Telemetry = telemetry.NewCollector()
+		addr, err := game.Telemetry.Serve(*metricsAddr)
+		if err != nil {
+			fatal("telemetry", err)
+		}
+		slog.Info("serving metrics", "url", "http://"+addr.String()+"/metrics")
+	}
+
 	if err := ebiten.RunGame(game); err != nil {
-		log.Fatal(err)
+		fatal("game", err)
 	}
 }
+
+// setupLogging installs the default slog logger, which the standard log
+// package also writes through.
+func setupLogging(level string, asJSON bool) error {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return err
+	}
+	opts := &slog.HandlerOptions{Level: lvl}
+	var h slog.Handler = slog.NewTextHandler(os.Stderr, opts)
+	if asJSON {
+		h = slog.NewJSONHandler(os.Stderr, opts)
+	}
+	slog.SetDefault(slog.New(h))
+	return nil
+}
+
+func fatal(msg string, err error) {
+	slog.Error(msg, "err", err)
+	os.Exit(1)
+}
@@ -0,0 +1,77 @@
+package render
+
+import (
+	"math"
+	"swarmlings/sim"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// maxBatchLings keeps every vertex of one DrawTriangles call addressable by
+// a uint16 index.
+const maxBatchLings = (math.MaxUint16 + 1) / 3
+
+// The back corners of each triangle sit at +-135 degrees from the heading.
+var backCos, backSin = math.Cos(math.Pi * 3 / 4), math.Sin(math.Pi * 3 / 4)
+
+// lingBatch holds one triangle per ling in buffers reused across frames.
+type lingBatch struct {
+	vertices []ebiten.Vertex
+	indices  []uint16
+}
+
+// build fills the buffers with every ling in screen space. tint, if not nil,
+// gives the color of ling i; lings are white otherwise.
+func (b *lingBatch) build(lings []sim.Ling, cam *Camera, tint func(i int) (r, g, b float32)) {
+	n := len(lings) * 3
+	if cap(b.vertices) < n {
+		b.vertices = make([]ebiten.Vertex, n)
+	}
+	b.vertices = b.vertices[:n]
+	// Triangles don't share vertices, so the indices are the same 0, 1, 2, ...
+	// sequence for every chunk and only need to be written once.
+	if m := min(n, maxBatchLings*3); len(b.indices) < m {
+		b.indices = make([]uint16, m)
+		for i := range b.indices {
+			b.indices[i] = uint16(i)
+		}
+	}
+
+	for i, l := range lings {
+		var cr, cg, cb float32 = 1, 1, 1
+		if tint != nil {
+			cr, cg, cb = tint(i)
+		}
+		// Same heading as atan2, without the trig per vertex.
+		hx, hy := 1.0, 0.0
+		if speed := math.Hypot(l.VX, l.VY); speed > 0 {
+			hx, hy = l.VX/speed, l.VY/speed
+		}
+		size := l.Size * cam.Zoom
+		cx, cy := cam.toScreen(l.X, l.Y)
+		corners := [3][2]float64{
+			{hx, hy},
+			{hx*backCos - hy*backSin, hx*backSin + hy*backCos},
+			{hx*backCos + hy*backSin, -hx*backSin + hy*backCos},
+		}
+		v := b.vertices[i*3 : i*3+3]
+		for j, c := range corners {
+			v[j] = ebiten.Vertex{
+				DstX:   cx + float32(size*c[0]),
+				DstY:   cy + float32(size*c[1]),
+				ColorR: cr,
+				ColorG: cg,
+				ColorB: cb,
+				ColorA: 1,
+			}
+		}
+	}
+}
+
+// draw issues one DrawTriangles call per maxBatchLings lings.
+func (b *lingBatch) draw(dst, texture *ebiten.Image) {
+	for start := 0; start < len(b.vertices); start += maxBatchLings * 3 {
+		end := min(start+maxBatchLings*3, len(b.vertices))
+		dst.DrawTriangles(b.vertices[start:end], b.indices[:end-start], texture, nil)
+	}
+}
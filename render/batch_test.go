@@ -0,0 +1,96 @@
+package render
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"swarmlings/sim"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// legacyVertices is the per-ling construction the batch replaced, kept as
+// the reference for the test and the baseline for the benchmark.
+func legacyVertices(ling sim.Ling, cam *Camera) ([]ebiten.Vertex, []uint16) {
+	angle := math.Atan2(ling.VY, ling.VX)
+	size := ling.Size * cam.Zoom
+	cx, cy := cam.toScreen(ling.X, ling.Y)
+	vertices := make([]ebiten.Vertex, 3)
+	offsets := []float64{0, math.Pi * 3 / 4, -math.Pi * 3 / 4}
+	for i := range 3 {
+		vertices[i] = ebiten.Vertex{
+			DstX:   cx + float32(size*math.Cos(angle+offsets[i])),
+			DstY:   cy + float32(size*math.Sin(angle+offsets[i])),
+			ColorR: 1,
+			ColorG: 1,
+			ColorB: 1,
+			ColorA: 1,
+		}
+	}
+	return vertices, []uint16{0, 1, 2}
+}
+
+func randomLings(n int) []sim.Ling {
+	rng := rand.New(rand.NewSource(1))
+	lings := make([]sim.Ling, n)
+	for i := range lings {
+		lings[i] = sim.Ling{X: rng.Float64() * 800, Y: rng.Float64() * 600, VX: rng.Float64()*2 - 1, VY: rng.Float64()*2 - 1, Size: 5}
+	}
+	return lings
+}
+
+func TestBatchBuild(t *testing.T) {
+	cam := &Camera{X: 400, Y: 300, Zoom: 1.5, screenW: 800, screenH: 600}
+	testCases := []struct {
+		desc    string
+		lings   []sim.Ling
+		indices int
+	}{
+		{desc: "matches per-ling triangles", lings: randomLings(100), indices: 300},
+		{desc: "stationary ling faces right", lings: []sim.Ling{{X: 10, Y: 10, Size: 5}}, indices: 3},
+		{desc: "indices stop at the uint16 limit", lings: randomLings(maxBatchLings + 10), indices: maxBatchLings * 3},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			var b lingBatch
+			b.build(tC.lings, cam, nil)
+			if len(b.indices) != tC.indices {
+				t.Errorf("expected %d indices, got %d", tC.indices, len(b.indices))
+			}
+			for i, l := range tC.lings {
+				want, _ := legacyVertices(l, cam)
+				for j := range 3 {
+					got := b.vertices[i*3+j]
+					if math.Abs(float64(got.DstX-want[j].DstX)) > 1e-3 || math.Abs(float64(got.DstY-want[j].DstY)) > 1e-3 {
+						t.Fatalf("ling %d vertex %d: expected (%v, %v), got (%v, %v)", i, j, want[j].DstX, want[j].DstY, got.DstX, got.DstY)
+					}
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkFrameBuild compares building one frame's vertices per ling, as
+// drawLing did, against filling the shared batch.
+func BenchmarkFrameBuild(b *testing.B) {
+	cam := &Camera{X: 400, Y: 300, Zoom: 1, screenW: 800, screenH: 600}
+	for _, n := range []int{1000, 10000, 100000} {
+		lings := randomLings(n)
+		b.Run(fmt.Sprintf("per-ling/%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for range b.N {
+				for _, l := range lings {
+					legacyVertices(l, cam)
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("batch/%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			var batch lingBatch
+			for range b.N {
+				batch.build(lings, cam, nil)
+			}
+		})
+	}
+}
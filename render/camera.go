@@ -0,0 +1,114 @@
+package render
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+const (
+	minZoom = 0.05
+	maxZoom = 20
+	// panSpeed is how far the arrow keys move the view per tick, in screen
+	// pixels, so panning feels the same at every zoom.
+	panSpeed = 10
+)
+
+// Camera maps world coordinates to the screen. X, Y is the world point
+// shown at the center of the screen.
+type Camera struct {
+	X, Y float64
+	Zoom float64
+	// Screen size in pixels, from Layout.
+	screenW, screenH int
+	dragging         bool
+	dragX, dragY     int
+}
+
+func (c *Camera) toScreen(x, y float64) (float32, float32) {
+	return float32((x-c.X)*c.Zoom + float64(c.screenW)/2), float32((y-c.Y)*c.Zoom + float64(c.screenH)/2)
+}
+
+func (c *Camera) toWorld(sx, sy int) (float64, float64) {
+	return (float64(sx)-float64(c.screenW)/2)/c.Zoom + c.X, (float64(sy)-float64(c.screenH)/2)/c.Zoom + c.Y
+}
+
+// zoomAt scales the view by factor, keeping the world point under the
+// screen position sx, sy fixed.
+func (c *Camera) zoomAt(sx, sy int, factor float64) {
+	wx, wy := c.toWorld(sx, sy)
+	c.Zoom = math.Max(minZoom, math.Min(maxZoom, c.Zoom*factor))
+	nx, ny := c.toWorld(sx, sy)
+	c.X += wx - nx
+	c.Y += wy - ny
+}
+
+// fit centers the world and zooms so all of it is visible.
+func (c *Camera) fit(worldW, worldH int) {
+	c.X, c.Y, c.Zoom = c.fitView(worldW, worldH)
+}
+
+// fitView returns the center and zoom that show the whole world.
+func (c *Camera) fitView(worldW, worldH int) (x, y, zoom float64) {
+	zoom = math.Min(float64(c.screenW)/float64(worldW), float64(c.screenH)/float64(worldH))
+	return float64(worldW) / 2, float64(worldH) / 2, math.Max(minZoom, math.Min(maxZoom, zoom))
+}
+
+// fitted reports whether the view is still the one fit would pick, i.e.
+// the user hasn't panned or zoomed since.
+func (c *Camera) fitted(worldW, worldH int) bool {
+	x, y, zoom := c.fitView(worldW, worldH)
+	const eps = 1e-9
+	return math.Abs(c.X-x) < eps && math.Abs(c.Y-y) < eps && math.Abs(c.Zoom-zoom) < eps
+}
+
+// sees reports whether any part of the world is on screen.
+func (c *Camera) sees(worldW, worldH int) bool {
+	x0, y0 := c.toScreen(0, 0)
+	x1, y1 := c.toScreen(float64(worldW), float64(worldH))
+	return x1 > 0 && y1 > 0 && x0 < float32(c.screenW) && y0 < float32(c.screenH)
+}
+
+// update pans with the arrow keys or a right/middle-button drag and zooms
+// with the wheel. overUI reports whether the cursor is over the panel,
+// which keeps the wheel and drags for the widgets; typing reports whether a
+// panel text input has focus, which keeps the arrow keys for its caret.
+func (c *Camera) update(overUI, typing bool) {
+	if c.Zoom == 0 {
+		return
+	}
+	if !typing {
+		step := panSpeed / c.Zoom
+		if ebiten.IsKeyPressed(ebiten.KeyArrowLeft) {
+			c.X -= step
+		}
+		if ebiten.IsKeyPressed(ebiten.KeyArrowRight) {
+			c.X += step
+		}
+		if ebiten.IsKeyPressed(ebiten.KeyArrowUp) {
+			c.Y -= step
+		}
+		if ebiten.IsKeyPressed(ebiten.KeyArrowDown) {
+			c.Y += step
+		}
+	}
+
+	mx, my := ebiten.CursorPosition()
+	dragButton := ebiten.IsMouseButtonPressed(ebiten.MouseButtonRight) || ebiten.IsMouseButtonPressed(ebiten.MouseButtonMiddle)
+	switch {
+	case !dragButton:
+		c.dragging = false
+	case c.dragging:
+		c.X -= float64(mx-c.dragX) / c.Zoom
+		c.Y -= float64(my-c.dragY) / c.Zoom
+		c.dragX, c.dragY = mx, my
+	case !overUI && (inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) || inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonMiddle)):
+		c.dragging = true
+		c.dragX, c.dragY = mx, my
+	}
+
+	if _, wy := ebiten.Wheel(); wy != 0 && !overUI {
+		c.zoomAt(mx, my, math.Pow(1.1, wy))
+	}
+}
@@ -0,0 +1,36 @@
+package render
+
+import "math"
+
+// hsvToRGB converts hue in degrees and saturation/value in [0, 1].
+func hsvToRGB(h, s, v float64) (r, g, b float32) {
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+	c := v * s
+	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := v - c
+	var rf, gf, bf float64
+	switch {
+	case h < 60:
+		rf, gf, bf = c, x, 0
+	case h < 120:
+		rf, gf, bf = x, c, 0
+	case h < 180:
+		rf, gf, bf = 0, c, x
+	case h < 240:
+		rf, gf, bf = 0, x, c
+	case h < 300:
+		rf, gf, bf = x, 0, c
+	default:
+		rf, gf, bf = c, 0, x
+	}
+	return float32(rf + m), float32(gf + m), float32(bf + m)
+}
+
+// flockColor spreads flock IDs around the hue wheel by the golden angle so
+// neighboring IDs get clearly different colors.
+func flockColor(id int) (r, g, b float32) {
+	return hsvToRGB(float64(id)*137.508, 0.65, 1)
+}
@@ -0,0 +1,53 @@
+package render
+
+import (
+	"math/rand"
+	"swarmlings/config"
+	"swarmlings/sim"
+)
+
+// These methods let server.Server drive the game. They run on the game
+// loop via Remote.Poll, so they need no locking.
+
+func (g *Game) Sim() *sim.World {
+	return g.World
+}
+
+func (g *Game) Params() config.Config {
+	return *g.Cfg
+}
+
+func (g *Game) SetParams(cfg config.Config) {
+	g.applyConfig(cfg)
+}
+
+func (g *Game) Paused() bool {
+	return g.play.Paused()
+}
+
+func (g *Game) SetPaused(paused bool) {
+	g.play.SetPaused(paused)
+	g.playbackChanged()
+}
+
+func (g *Game) Step() {
+	g.play.Step()
+	g.playbackChanged()
+}
+
+// Reset replaces the population with a fresh one from Spawner and restarts
+// the tick count and recorded history. A Spawner without a population
+// keeps the current count.
+func (g *Game) Reset() {
+	spawner := g.Spawner
+	if spawner.Population == 0 {
+		spawner.Population = len(g.World.Lings)
+	}
+	g.World.Lings = spawner.Spawn(g.World.Width, g.World.Height, rand.Int63())
+	g.World.Tick = 0
+	g.World.Trails.Reset()
+	g.heat.reset()
+	g.deselect()
+	g.Flocks = nil
+	g.history = nil
+}
@@ -0,0 +1,136 @@
+package render
+
+import (
+	"image/color"
+	"math"
+	"slices"
+	"strconv"
+	"swarmlings/sim"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	// forceArrowScale turns a per-tick velocity change into screen pixels.
+	forceArrowScale = 300
+	// maxForceArrow caps arrow length so close-range avoidance stays
+	// readable.
+	maxForceArrow = 40
+)
+
+// Force arrow colors, also listed in the status line.
+var forceColors = [4][3]float32{
+	{0.95, 0.35, 0.35}, // avoid
+	{0.35, 0.6, 1},     // align
+	{0.4, 0.9, 0.4},    // gather
+	{1, 0.85, 0.3},     // wall
+}
+
+// debugOverlays are the debug layers besides the radii drawn by D.
+type debugOverlays struct {
+	grid, links, forces bool
+	counts              []int
+}
+
+// syncSteering records per-ling steering only while an overlay shows it,
+// and drops what was recorded once none does so it can't go stale. The
+// inspector only needs its own ling's breakdown.
+func (g *Game) syncSteering() {
+	g.World.RecordSteering = g.debug.links || g.debug.forces
+	if !g.World.RecordSteering {
+		g.World.Steering = g.World.Steering[:0]
+	}
+	g.World.Inspect = -1
+	if g.inspect.selected {
+		g.World.Inspect = g.inspect.index
+	}
+}
+
+func (g *Game) drawRadii(screen *ebiten.Image) {
+	detect := float32(g.World.DetectionRadius * g.camera.Zoom)
+	avoid := float32(g.World.AvoidanceRadius * g.camera.Zoom)
+	for _, l := range g.World.Lings {
+		cx, cy := g.camera.toScreen(l.X, l.Y)
+		vector.StrokeCircle(screen, cx, cy, detect, 1, color.RGBA{80, 80, 80, 80}, true)
+		vector.StrokeCircle(screen, cx, cy, avoid, 1, color.RGBA{0, 180, 0, 80}, true)
+	}
+}
+
+// drawGrid outlines the spatial grid and labels occupied cells that are
+// big enough on screen to fit a number.
+func (g *Game) drawGrid(screen *ebiten.Image) {
+	v := g.World.GridView(g.debug.counts)
+	g.debug.counts = v.Counts
+	if v.Cols == 0 {
+		return
+	}
+	g.lines.reset()
+	x0, y0 := g.camera.toScreen(0, 0)
+	x1, y1 := g.camera.toScreen(float64(g.World.Width), float64(g.World.Height))
+	for c := 1; c < v.Cols; c++ {
+		x, _ := g.camera.toScreen(float64(c)*v.CellSize, 0)
+		g.lines.add(x, y0, x, y1, 1, 0.5, 0.5, 0.6, 0.5)
+	}
+	for r := 1; r < v.Rows; r++ {
+		_, y := g.camera.toScreen(0, float64(r)*v.CellSize)
+		g.lines.add(x0, y, x1, y, 1, 0.5, 0.5, 0.6, 0.5)
+	}
+	g.lines.draw(screen, g.Texture)
+
+	if v.CellSize*g.camera.Zoom < 20 {
+		return
+	}
+	bounds := screen.Bounds()
+	for i, n := range v.Counts {
+		if n == 0 {
+			continue
+		}
+		x, y := g.camera.toScreen(float64(i%v.Cols)*v.CellSize, float64(i/v.Cols)*v.CellSize)
+		if int(x) > bounds.Max.X || int(y) > bounds.Max.Y || x < -float32(v.CellSize*g.camera.Zoom) || y < -float32(v.CellSize*g.camera.Zoom) {
+			continue
+		}
+		ebitenutil.DebugPrintAt(screen, strconv.Itoa(n), int(x)+3, int(y)+1)
+	}
+}
+
+// drawSteering draws links to the neighbors each ling used, brighter red
+// inside the avoidance radius, and its steering terms as arrows.
+func (g *Game) drawSteering(screen *ebiten.Image) {
+	lings := g.World.Lings
+	if len(g.World.Steering) != len(lings) {
+		return
+	}
+	g.lines.reset()
+	avoid2 := g.World.AvoidanceRadius * g.World.AvoidanceRadius
+	for i, st := range g.World.Steering {
+		cx, cy := g.camera.toScreen(lings[i].X, lings[i].Y)
+		if g.debug.links {
+			for _, j := range st.Neighbors {
+				// Links are symmetric; draw each pair once.
+				if j < i && slices.Contains(g.World.Steering[j].Neighbors, i) {
+					continue
+				}
+				nx, ny := g.camera.toScreen(lings[j].X, lings[j].Y)
+				dx, dy := lings[j].X-lings[i].X, lings[j].Y-lings[i].Y
+				if dx*dx+dy*dy < avoid2 {
+					g.lines.add(cx, cy, nx, ny, 1, 1, 0.4, 0.4, 0.6)
+				} else {
+					g.lines.add(cx, cy, nx, ny, 1, 0.6, 0.6, 0.7, 0.25)
+				}
+			}
+		}
+		if g.debug.forces {
+			for k, f := range []sim.Vec{st.Avoid, st.Align, st.Gather, st.Wall} {
+				ax, ay := float32(f.X*forceArrowScale), float32(f.Y*forceArrowScale)
+				if l := float32(math.Hypot(float64(ax), float64(ay))); l > maxForceArrow {
+					ax, ay = ax/l*maxForceArrow, ay/l*maxForceArrow
+				}
+				c := forceColors[k]
+				g.lines.arrow(cx, cy, cx+ax, cy+ay, 1.5, c[0], c[1], c[2], 0.9)
+			}
+		}
+	}
+	g.lines.draw(screen, g.Texture)
+}
@@ -0,0 +1,165 @@
+package render
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"swarmlings/sim"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// historyLength is how many samples the graph panel keeps, one per tick.
+const historyLength = 3000
+
+// series is a fixed-size ring buffer of samples, oldest first.
+type series struct {
+	label  string
+	format string
+	color  color.RGBA
+	// lo and hi pin the vertical range; if lo == hi it follows the data.
+	lo, hi float64
+	values []float64
+	next   int
+	full   bool
+}
+
+func newSeries(label, format string, clr color.RGBA, lo, hi float64) *series {
+	return &series{label: label, format: format, color: clr, lo: lo, hi: hi, values: make([]float64, historyLength)}
+}
+
+func (s *series) push(v float64) {
+	s.values[s.next] = v
+	s.next++
+	if s.next == len(s.values) {
+		s.next = 0
+		s.full = true
+	}
+}
+
+func (s *series) len() int {
+	if s.full {
+		return len(s.values)
+	}
+	return s.next
+}
+
+func (s *series) at(i int) float64 {
+	if s.full {
+		return s.values[(s.next+i)%len(s.values)]
+	}
+	return s.values[i]
+}
+
+// metricsHistory holds the time series plotted by the graph panel.
+type metricsHistory struct {
+	population   *series
+	polarization *series
+	speed        *series
+	fps          *series
+	path         vector.Path
+}
+
+func newMetricsHistory() *metricsHistory {
+	return &metricsHistory{
+		population:   newSeries("Population", "%.0f", color.RGBA{220, 220, 230, 255}, 0, 0),
+		polarization: newSeries("Polarization", "%.3f", color.RGBA{0, 180, 200, 255}, 0, 1),
+		speed:        newSeries("Avg speed", "%.2f", color.RGBA{230, 170, 60, 255}, 0, 0),
+		fps:          newSeries("FPS", "%.0f", color.RGBA{120, 200, 120, 255}, 0, 0),
+	}
+}
+
+func (h *metricsHistory) record(m sim.Metrics, fps float64) {
+	h.population.push(float64(m.Population))
+	h.polarization.push(m.Polarization)
+	h.speed.push(m.AverageSpeed)
+	h.fps.push(fps)
+}
+
+// draw stacks one chart per series in a panel whose top-right corner is at
+// (right, top).
+func (h *metricsHistory) draw(screen *ebiten.Image, right, top float32, scale float64) {
+	all := []*series{h.population, h.polarization, h.speed, h.fps}
+	pad := float32(math.Round(8 * scale))
+	chartW := float32(math.Round(300 * scale))
+	chartH := float32(math.Round(60 * scale))
+	labelH := float32(16)
+	w := chartW + 2*pad
+	ht := pad + float32(len(all))*(labelH+chartH+pad)
+	left := right - w
+
+	vector.FillRect(screen, left, top, w, ht, color.NRGBA{20, 20, 24, 220}, false)
+	vector.StrokeRect(screen, left, top, w, ht, 1, color.NRGBA{60, 60, 70, 255}, false)
+
+	y := top + pad
+	for _, s := range all {
+		n := s.len()
+		label := s.label
+		if n > 0 {
+			label += ": " + fmt.Sprintf(s.format, s.at(n-1))
+		}
+		ebitenutil.DebugPrintAt(screen, label, int(left+pad), int(y))
+		y += labelH
+		vector.FillRect(screen, left+pad, y, chartW, chartH, color.NRGBA{12, 12, 16, 255}, false)
+		h.drawSeries(screen, s, left+pad, y, chartW, chartH)
+		y += chartH + pad
+	}
+}
+
+// drawSeries plots the whole buffer across width, so the x-axis always spans
+// historyLength samples and new samples enter from the right.
+func (h *metricsHistory) drawSeries(screen *ebiten.Image, s *series, x, y, width, height float32) {
+	n := s.len()
+	if n < 2 {
+		return
+	}
+	lo, hi := s.lo, s.hi
+	if lo == hi {
+		lo, hi = math.Inf(1), math.Inf(-1)
+		for i := range n {
+			v := s.at(i)
+			lo = math.Min(lo, v)
+			hi = math.Max(hi, v)
+		}
+		if hi-lo < 1e-9 {
+			lo, hi = lo-1, hi+1
+		}
+	}
+
+	// One point per pixel column is plenty; average the samples that share it.
+	cols := int(width)
+	step := float32(width) / float32(historyLength)
+	offset := historyLength - n
+	h.path.Reset()
+	started := false
+	for c := 0; c < cols; c++ {
+		from := int(float32(c)/step) - offset
+		to := int(float32(c+1)/step) - offset
+		from = max(from, 0)
+		to = min(to, n)
+		if from >= to {
+			continue
+		}
+		var sum float64
+		for i := from; i < to; i++ {
+			sum += s.at(i)
+		}
+		v := (sum/float64(to-from) - lo) / (hi - lo)
+		px := x + float32(c)
+		py := y + height - float32(v)*height
+		if !started {
+			h.path.MoveTo(px, py)
+			started = true
+		} else {
+			h.path.LineTo(px, py)
+		}
+	}
+	if !started {
+		return
+	}
+	op := &vector.DrawPathOptions{AntiAlias: true}
+	op.ColorScale.ScaleWithColor(s.color)
+	vector.StrokePath(screen, &h.path, &vector.StrokeOptions{Width: 1.5}, op)
+}
@@ -0,0 +1,146 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"log/slog"
+	"math"
+	"os"
+	"swarmlings/sim"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+type heatMode int
+
+const (
+	heatOff heatMode = iota
+	// heatDensity shows where lings are this frame.
+	heatDensity
+	// heatVisits shows where lings have spent time since the last reset,
+	// including ticks while the overlay was hidden.
+	heatVisits
+)
+
+const (
+	// heatCellSize is the heatmap resolution in world units.
+	heatCellSize = 10
+	// heatBlur is the live density blur radius in cells.
+	heatBlur = 2
+	// heatAlpha is the overlay opacity at the hottest cell.
+	heatAlpha = 0.75
+)
+
+type heatOverlay struct {
+	mode   heatMode
+	live   *sim.Heatmap
+	visits *sim.Heatmap
+	img    *ebiten.Image
+	pixels []byte
+}
+
+func (h *heatOverlay) cycle() {
+	h.mode = (h.mode + 1) % 3
+}
+
+// record adds the current positions to the time-spent map, starting over
+// if the world changed size. It runs every tick whatever the mode, so an
+// export covers the whole run; binning is one pass over the lings.
+func (h *heatOverlay) record(w *sim.World) {
+	if h.visits == nil || !h.visits.Fits(w.Width, w.Height, heatCellSize) {
+		h.visits = sim.NewHeatmap(w.Width, w.Height, heatCellSize)
+	}
+	h.visits.Add(w.Lings)
+}
+
+func (h *heatOverlay) reset() {
+	h.visits = nil
+}
+
+// heatLevel normalizes v against top. Time spent piles up in a few cells,
+// so it is shown on a log scale to keep the rest visible.
+func heatLevel(v, top float64, logScale bool) float64 {
+	if top <= 0 {
+		return 0
+	}
+	if logScale {
+		return math.Log1p(v) / math.Log1p(top)
+	}
+	return v / top
+}
+
+func (h *heatOverlay) draw(screen *ebiten.Image, w *sim.World, cam *Camera) {
+	var hm *sim.Heatmap
+	switch h.mode {
+	case heatDensity:
+		if h.live == nil || !h.live.Fits(w.Width, w.Height, heatCellSize) {
+			h.live = sim.NewHeatmap(w.Width, w.Height, heatCellSize)
+		}
+		h.live.Clear()
+		h.live.Add(w.Lings)
+		h.live.Blur(heatBlur)
+		hm = h.live
+	case heatVisits:
+		hm = h.visits
+	}
+	if hm == nil {
+		return
+	}
+
+	if h.img == nil || h.img.Bounds().Dx() != hm.Cols || h.img.Bounds().Dy() != hm.Rows {
+		if h.img != nil {
+			h.img.Deallocate()
+		}
+		h.img = ebiten.NewImage(hm.Cols, hm.Rows)
+		h.pixels = make([]byte, hm.Cols*hm.Rows*4)
+	}
+	top := hm.Max()
+	for i, v := range hm.Values {
+		t := heatLevel(v, top, h.mode == heatVisits)
+		r, g, b := rampColor(t)
+		// WritePixels takes premultiplied alpha.
+		a := float32(t * heatAlpha)
+		h.pixels[i*4] = uint8(r * a * 255)
+		h.pixels[i*4+1] = uint8(g * a * 255)
+		h.pixels[i*4+2] = uint8(b * a * 255)
+		h.pixels[i*4+3] = uint8(a * 255)
+	}
+	h.img.WritePixels(h.pixels)
+
+	op := &ebiten.DrawImageOptions{Filter: ebiten.FilterLinear}
+	op.GeoM.Scale(hm.CellSize*cam.Zoom, hm.CellSize*cam.Zoom)
+	x, y := cam.toScreen(0, 0)
+	op.GeoM.Translate(float64(x), float64(y))
+	screen.DrawImage(h.img, op)
+}
+
+// export writes the time-spent map as an opaque PNG, one pixel per cell.
+func (h *heatOverlay) export(path string) error {
+	if h.visits == nil {
+		return fmt.Errorf("nothing recorded yet")
+	}
+	hm := h.visits
+	img := image.NewNRGBA(image.Rect(0, 0, hm.Cols, hm.Rows))
+	top := hm.Max()
+	for i, v := range hm.Values {
+		t := heatLevel(v, top, true)
+		r, g, b := rampColor(t)
+		// Fade to black where lings never went.
+		img.Set(i%hm.Cols, i/hm.Cols, color.NRGBA{uint8(r * float32(t) * 255), uint8(g * float32(t) * 255), uint8(b * float32(t) * 255), 255})
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	slog.Info("heatmap exported", "path", path, "cols", hm.Cols, "rows", hm.Rows)
+	return nil
+}
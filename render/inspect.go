@@ -0,0 +1,111 @@
+package render
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// pickRadius is how close to a ling a click must land, in screen pixels.
+const pickRadius = 10
+
+// inspector tracks the ling picked by clicking on it.
+type inspector struct {
+	index    int
+	selected bool
+	follow   bool
+	// since is the tick of the selection; the world's breakdown is for this
+	// ling once an Update has run after it.
+	since int
+}
+
+func (g *Game) deselect() {
+	g.inspect.selected = false
+	g.inspect.follow = false
+	g.syncSteering()
+}
+
+// updateInspector handles selection clicks and keeps a followed ling in
+// the middle of the view. typing leaves L and Esc to a focused text input.
+func (g *Game) updateInspector(overUI, typing bool) {
+	if g.inspect.selected && g.inspect.index >= len(g.World.Lings) {
+		g.deselect()
+	}
+	_, brush := g.Tools.brush()
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) && !overUI && !brush {
+		wx, wy := g.camera.toWorld(ebiten.CursorPosition())
+		if i := g.World.LingAt(wx, wy, pickRadius/g.camera.Zoom); i >= 0 {
+			g.inspect.index, g.inspect.selected, g.inspect.since = i, true, g.World.Tick
+			g.syncSteering()
+		} else {
+			g.deselect()
+		}
+	}
+	if !typing && inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		g.deselect()
+	}
+	if !typing && inpututil.IsKeyJustPressed(ebiten.KeyL) && g.inspect.selected {
+		g.inspect.follow = !g.inspect.follow
+	}
+	if g.inspect.follow {
+		l := g.World.Lings[g.inspect.index]
+		g.camera.X, g.camera.Y = l.X, l.Y
+	}
+}
+
+// drawInspector circles the selected ling and lists its state in the
+// bottom-right corner, left of the parameter panel.
+func (g *Game) drawInspector(screen *ebiten.Image) {
+	if !g.inspect.selected {
+		return
+	}
+	i := g.inspect.index
+	l := g.World.Lings[i]
+	cx, cy := g.camera.toScreen(l.X, l.Y)
+	vector.StrokeCircle(screen, cx, cy, pickRadius, 1.5, color.RGBA{255, 255, 255, 200}, true)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Ling %d", i)
+	if g.inspect.follow {
+		b.WriteString("  (following)")
+	}
+	fmt.Fprintf(&b, "\npos     %7.1f %7.1f", l.X, l.Y)
+	fmt.Fprintf(&b, "\nvel     %7.3f %7.3f", l.VX, l.VY)
+	fmt.Fprintf(&b, "\nspeed   %7.3f", math.Hypot(l.VX, l.VY))
+	if g.World.Tick > g.inspect.since {
+		st := g.World.Inspected
+		fmt.Fprintf(&b, "\nneighbors %d", len(st.Neighbors))
+		fmt.Fprintf(&b, "\navoid   %7.3f %7.3f", st.Avoid.X, st.Avoid.Y)
+		fmt.Fprintf(&b, "\nalign   %7.3f %7.3f", st.Align.X, st.Align.Y)
+		fmt.Fprintf(&b, "\ngather  %7.3f %7.3f", st.Gather.X, st.Gather.Y)
+		fmt.Fprintf(&b, "\nwall    %7.3f %7.3f", st.Wall.X, st.Wall.Y)
+	} else {
+		b.WriteString("\nforces after the next tick")
+	}
+	b.WriteString("\n\nL: follow  Esc: deselect")
+	text := b.String()
+
+	// The debug font is 6x16 pixels per character.
+	const pad = 8
+	lines := strings.Split(text, "\n")
+	w := float32(0)
+	for _, line := range lines {
+		w = max(w, float32(len(line)*6))
+	}
+	w += 2 * pad
+	h := float32(len(lines)*16 + 2*pad)
+	right := float32(screen.Bounds().Dx()) - 10
+	if left, ok := g.panelLeft(); ok {
+		right = float32(left) - 10
+	}
+	left, top := right-w, float32(screen.Bounds().Dy())-10-h
+	vector.FillRect(screen, left, top, w, h, color.NRGBA{20, 20, 24, 220}, false)
+	vector.StrokeRect(screen, left, top, w, h, 1, color.NRGBA{60, 60, 70, 255}, false)
+	ebitenutil.DebugPrintAt(screen, text, int(left+pad), int(top+pad))
+}
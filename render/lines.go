@@ -0,0 +1,64 @@
+package render
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// lineBatch collects line segments as quads and draws them in as few
+// DrawTriangles calls as the uint16 index limit allows.
+type lineBatch struct {
+	vertices []ebiten.Vertex
+	indices  []uint16
+}
+
+func (b *lineBatch) reset() {
+	b.vertices = b.vertices[:0]
+}
+
+// add appends a segment in screen space. The color is straight alpha.
+func (b *lineBatch) add(x0, y0, x1, y1, width, r, g, bl, a float32) {
+	dx, dy := x1-x0, y1-y0
+	l := float32(math.Hypot(float64(dx), float64(dy)))
+	if l == 0 {
+		return
+	}
+	nx, ny := -dy/l*width/2, dx/l*width/2
+	for _, p := range [4][2]float32{{x0 + nx, y0 + ny}, {x0 - nx, y0 - ny}, {x1 + nx, y1 + ny}, {x1 - nx, y1 - ny}} {
+		b.vertices = append(b.vertices, ebiten.Vertex{
+			DstX:   p[0],
+			DstY:   p[1],
+			ColorR: r * a,
+			ColorG: g * a,
+			ColorB: bl * a,
+			ColorA: a,
+		})
+	}
+}
+
+// arrow appends a segment with a two-stroke head at x1, y1.
+func (b *lineBatch) arrow(x0, y0, x1, y1, width, r, g, bl, a float32) {
+	b.add(x0, y0, x1, y1, width, r, g, bl, a)
+	dx, dy := x1-x0, y1-y0
+	l := float32(math.Hypot(float64(dx), float64(dy)))
+	if l == 0 {
+		return
+	}
+	head := min(6, l/2)
+	ux, uy := dx/l*head, dy/l*head
+	b.add(x1, y1, x1-ux-uy/2, y1-uy+ux/2, width, r, g, bl, a)
+	b.add(x1, y1, x1-ux+uy/2, y1-uy-ux/2, width, r, g, bl, a)
+}
+
+func (b *lineBatch) draw(dst, texture *ebiten.Image) {
+	quads := len(b.vertices) / 4
+	if m := min(quads, maxQuads) * 6; len(b.indices) < m {
+		b.indices = quadIndices(m / 6)
+	}
+	const chunk = maxQuads * 4
+	for start := 0; start < len(b.vertices); start += chunk {
+		end := min(start+chunk, len(b.vertices))
+		dst.DrawTriangles(b.vertices[start:end], b.indices[:(end-start)/4*6], texture, nil)
+	}
+}
@@ -0,0 +1,82 @@
+package render
+
+import (
+	"math"
+	"strconv"
+)
+
+// Speed multipliers are powers of two from 0.25x to 16x.
+const (
+	minSpeedExp = -2
+	maxSpeedExp = 4
+)
+
+// Playback decides how many simulation ticks each frame runs. The zero
+// value runs at 1x.
+type Playback struct {
+	paused bool
+	steps  int
+	// speedExp is log2 of the speed multiplier.
+	speedExp int
+	// carry accumulates fractional ticks below 1x.
+	carry float64
+}
+
+func (p *Playback) Paused() bool {
+	return p.paused
+}
+
+func (p *Playback) SetPaused(paused bool) {
+	p.paused = paused
+	p.steps = 0
+}
+
+func (p *Playback) TogglePause() {
+	p.SetPaused(!p.paused)
+}
+
+// Step queues a single tick. Stepping a running simulation pauses it
+// instead, so the next step starts from a still frame.
+func (p *Playback) Step() {
+	if !p.paused {
+		p.SetPaused(true)
+		return
+	}
+	p.steps++
+}
+
+func (p *Playback) Speed() float64 {
+	return math.Ldexp(1, p.speedExp)
+}
+
+func (p *Playback) Faster() {
+	p.setSpeedExp(p.speedExp + 1)
+}
+
+func (p *Playback) Slower() {
+	p.setSpeedExp(p.speedExp - 1)
+}
+
+func (p *Playback) setSpeedExp(exp int) {
+	p.speedExp = max(minSpeedExp, min(maxSpeedExp, exp))
+	p.carry = 0
+}
+
+func (p *Playback) SpeedLabel() string {
+	return strconv.FormatFloat(p.Speed(), 'g', -1, 64) + "x"
+}
+
+// ticks returns how many ticks to run this frame and consumes them.
+func (p *Playback) ticks() int {
+	if p.paused {
+		if p.steps > 0 {
+			p.steps--
+			return 1
+		}
+		return 0
+	}
+	p.carry += p.Speed()
+	n := int(p.carry)
+	p.carry -= float64(n)
+	return n
+}
@@ -0,0 +1,97 @@
+package render
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPlayback(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		setup  func(p *Playback)
+		want   []int
+		paused bool
+		speed  string
+	}{
+		{
+			desc:  "1x",
+			setup: func(p *Playback) {},
+			want:  []int{1, 1, 1},
+			speed: "1x",
+		},
+		{
+			desc:  "0.25x carries fractions across frames",
+			setup: func(p *Playback) { p.Slower(); p.Slower() },
+			want:  []int{0, 0, 0, 1, 0, 0, 0, 1},
+			speed: "0.25x",
+		},
+		{
+			desc:  "16x",
+			setup: func(p *Playback) { p.setSpeedExp(4) },
+			want:  []int{16, 16},
+			speed: "16x",
+		},
+		{
+			desc: "speed change drops the carry",
+			setup: func(p *Playback) {
+				p.Slower()
+				p.ticks()
+				p.Faster()
+				p.Slower()
+			},
+			want:  []int{0, 1},
+			speed: "0.5x",
+		},
+		{
+			desc:   "step while paused runs one tick each",
+			setup:  func(p *Playback) { p.SetPaused(true); p.Step(); p.Step() },
+			want:   []int{1, 1, 0},
+			paused: true,
+			speed:  "1x",
+		},
+		{
+			desc:   "step while running pauses",
+			setup:  func(p *Playback) { p.Step() },
+			want:   []int{0, 0},
+			paused: true,
+			speed:  "1x",
+		},
+		{
+			desc:  "resuming drops queued steps",
+			setup: func(p *Playback) { p.SetPaused(true); p.Step(); p.Step(); p.TogglePause() },
+			want:  []int{1, 1},
+			speed: "1x",
+		},
+		{
+			desc:  "clamped at the slowest speed",
+			setup: func(p *Playback) { p.setSpeedExp(minSpeedExp); p.Slower() },
+			want:  []int{0, 0, 0, 1},
+			speed: "0.25x",
+		},
+		{
+			desc:  "clamped at the fastest speed",
+			setup: func(p *Playback) { p.setSpeedExp(maxSpeedExp + 3); p.Faster() },
+			want:  []int{16},
+			speed: "16x",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			var p Playback
+			tC.setup(&p)
+			got := make([]int, len(tC.want))
+			for i := range got {
+				got[i] = p.ticks()
+			}
+			if !slices.Equal(got, tC.want) {
+				t.Errorf("expected ticks %v, got %v", tC.want, got)
+			}
+			if p.Paused() != tC.paused {
+				t.Errorf("expected paused %v, got %v", tC.paused, p.Paused())
+			}
+			if p.SpeedLabel() != tC.speed {
+				t.Errorf("expected speed %s, got %s", tC.speed, p.SpeedLabel())
+			}
+		})
+	}
+}
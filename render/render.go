@@ -2,12 +2,18 @@ package render
 
 import (
 	"swarmlings/config"
+	"swarmlings/server"
 	"swarmlings/sim"
+	"swarmlings/telemetry"
 	"fmt"
 	"image/color"
+	"log/slog"
 	"math"
+	"strings"
+	"time"
 
 	"github.com/ebitenui/ebitenui"
+	"github.com/ebitenui/ebitenui/widget"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
@@ -15,28 +21,175 @@ import (
 )
 
 type Game struct {
-	World     *sim.World
-	Cfg       *config.Config
-	Texture   *ebiten.Image
-	DebugMode bool
-	ShowUI    bool
-	Ui        ebitenui.UI
-	uiScale   float64
+	World       *sim.World
+	Cfg         *config.Config
+	LoadErr     error
+	Texture     *ebiten.Image
+	DebugMode   bool
+	ShowUI      bool
+	ShowGraphs  bool
+	Ui          ebitenui.UI
+	Watcher     *config.Watcher
+	Flocks      *sim.FlockTracker
+	Remote      *server.Server
+	Telemetry   *telemetry.Collector
+	Spawner     sim.Spawner
+	Resize      ResizeMode
+	TrailLength int
+	TrailSample int
+	Tools       Tools
+	uiErr       error
+	uiHooks     UIHooks
+	history     *metricsHistory
+	uiScale     float64
+	lastPoll    time.Time
+	play        Playback
+	camera      Camera
+	batch       lingBatch
+	lines       lineBatch
+	glow        glowRenderer
+	useGlow     bool
+	density     []int
+	debug       debugOverlays
+	inspect     inspector
+	heat        heatOverlay
 }
 
+const configPollInterval = 500 * time.Millisecond
+
+const defaultTrailLength = 40
+
 func (g *Game) toggleDebug() {
 	g.DebugMode = !g.DebugMode
 }
 
-func (g *Game) Update() error {
+func (g *Game) tick() {
+	start := time.Now()
+	g.World.Update()
+	if g.Telemetry != nil {
+		g.Telemetry.ObserveUpdate(time.Since(start), g.World)
+	}
+	if g.Remote != nil {
+		g.Remote.Publish(g.World)
+	}
+	g.heat.record(g.World)
+	// Recorded every tick, even while hidden, so the graphs span the same
+	// number of ticks at any speed and have history when opened.
+	if g.history == nil {
+		g.history = newMetricsHistory()
+	}
+	g.history.record(g.World.Metrics(), ebiten.ActualFPS())
+}
+
+// toggleTrails starts or stops recording trails in the world.
+func (g *Game) toggleTrails() {
+	if g.World.Trails != nil {
+		g.World.Trails = nil
+		return
+	}
+	length := g.TrailLength
+	if length <= 0 {
+		length = defaultTrailLength
+	}
+	g.World.Trails = sim.NewTrails(length)
+}
+
+// handleKeys runs the hotkeys. Update skips it while a panel text input
+// has focus, so typing values doesn't drive the sim.
+func (g *Game) handleKeys() {
 	if inpututil.IsKeyJustReleased(ebiten.KeyD) {
 		g.toggleDebug()
-		fmt.Println(g.DebugMode)
+		slog.Debug("debug mode toggled", "enabled", g.DebugMode)
+	}
+	if inpututil.IsKeyJustReleased(ebiten.Key1) {
+		g.debug.grid = !g.debug.grid
+	}
+	if inpututil.IsKeyJustReleased(ebiten.Key2) {
+		g.debug.links = !g.debug.links
+		g.syncSteering()
+	}
+	if inpututil.IsKeyJustReleased(ebiten.Key3) {
+		g.debug.forces = !g.debug.forces
+		g.syncSteering()
 	}
 	if inpututil.IsKeyJustReleased(ebiten.KeyTab) {
 		g.ShowUI = !g.ShowUI
 	}
-	g.World.Update()
+	if inpututil.IsKeyJustReleased(ebiten.KeyF) {
+		if g.Cfg.ColorScheme == "flock" {
+			g.setColorScheme("plain")
+		} else {
+			g.setColorScheme("flock")
+		}
+	}
+	if inpututil.IsKeyJustReleased(ebiten.KeyC) {
+		g.cycleColorScheme()
+	}
+	if inpututil.IsKeyJustReleased(ebiten.KeyT) {
+		g.toggleTrails()
+	}
+	if inpututil.IsKeyJustReleased(ebiten.KeyH) {
+		g.heat.cycle()
+	}
+	if inpututil.IsKeyJustReleased(ebiten.KeyP) {
+		path := fmt.Sprintf("heatmap-%d.png", g.World.Tick)
+		if err := g.heat.export(path); err != nil {
+			slog.Error("heatmap export failed", "path", path, "err", err)
+		}
+	}
+	if inpututil.IsKeyJustReleased(ebiten.KeyG) {
+		g.ShowGraphs = !g.ShowGraphs
+	}
+	if inpututil.IsKeyJustReleased(ebiten.KeyS) {
+		g.useGlow = !g.useGlow
+		g.glow.reset()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+		g.play.TogglePause()
+		g.playbackChanged()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyPeriod) {
+		g.play.Step()
+		g.playbackChanged()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEqual) || inpututil.IsKeyJustPressed(ebiten.KeyNumpadAdd) {
+		g.play.Faster()
+		g.playbackChanged()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyMinus) || inpututil.IsKeyJustPressed(ebiten.KeyNumpadSubtract) {
+		g.play.Slower()
+		g.playbackChanged()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyHome) || inpututil.IsKeyJustPressed(ebiten.Key0) {
+		g.camera.fit(g.World.Width, g.World.Height)
+	}
+}
+
+func (g *Game) Update() error {
+	typing := g.typing()
+	if !typing {
+		g.handleKeys()
+	}
+	overUI := g.cursorOverUI()
+	g.camera.update(overUI, typing)
+	g.updateInspector(overUI, typing)
+	g.applyTool(overUI)
+	if g.Watcher != nil && time.Since(g.lastPoll) >= configPollInterval {
+		g.lastPoll = time.Now()
+		g.reloadConfig()
+	}
+	if g.Remote != nil {
+		g.Remote.Poll()
+	}
+	for range g.play.ticks() {
+		g.tick()
+	}
+	if g.Cfg.ColorScheme == "flock" {
+		if g.Flocks == nil {
+			g.Flocks = sim.NewFlockTracker(0)
+		}
+		g.Flocks.Update(g.World)
+	}
 	if g.ShowUI {
 		g.Ui.Update()
 	}
@@ -44,13 +197,183 @@ func (g *Game) Update() error {
 }
 
 func (g *Game) Draw(screen *ebiten.Image) {
-	for _, b := range g.World.Lings {
-		g.drawLing(screen, b, g.Texture)
+	x0, y0 := g.camera.toScreen(0, 0)
+	x1, y1 := g.camera.toScreen(float64(g.World.Width), float64(g.World.Height))
+	vector.StrokeRect(screen, x0, y0, x1-x0, y1-y0, 1, color.RGBA{60, 60, 70, 255}, false)
+
+	g.heat.draw(screen, g.World, &g.camera)
+	tint := g.tint()
+	if g.World.Trails != nil {
+		g.lines.reset()
+		buildTrails(&g.lines, g.World.Trails, len(g.World.Lings), &g.camera, g.TrailSample, tint)
+		g.lines.draw(screen, g.Texture)
+	}
+	if g.useGlow && g.glow.ready() {
+		g.glow.draw(screen, g.World.Lings, &g.camera, tint)
+	} else {
+		g.batch.build(g.World.Lings, &g.camera, tint)
+		g.batch.draw(screen, g.Texture)
+	}
+	if g.DebugMode {
+		g.drawRadii(screen)
+	}
+	if g.debug.grid {
+		g.drawGrid(screen)
+	}
+	if g.debug.links || g.debug.forces {
+		g.drawSteering(screen)
 	}
+	g.drawInspector(screen)
+	g.drawBrush(screen)
+	g.drawLegend(screen)
 	if g.ShowUI {
 		g.Ui.Draw(screen)
 	}
-	ebitenutil.DebugPrint(screen, fmt.Sprintf("FPS: %.0f", ebiten.ActualFPS()))
+	if g.ShowGraphs && g.history != nil {
+		right := float32(screen.Bounds().Dx()) - 10
+		if left, ok := g.panelLeft(); ok {
+			right = float32(left) - 10
+		}
+		g.history.draw(screen, right, 10, g.uiScale)
+	}
+	status := fmt.Sprintf("FPS: %.0f  Tick: %d  Speed: %s", ebiten.ActualFPS(), g.World.Tick, g.play.SpeedLabel())
+	if g.play.Paused() {
+		status += "  (paused)"
+	}
+	if g.debug.forces {
+		status += "\nForces: avoid red, align blue, gather green, wall yellow"
+	}
+	if g.Cfg.ColorScheme == "flock" && g.Flocks != nil {
+		status += fmt.Sprintf("\nFlocks: %d  sizes %s", g.Flocks.Count(), flockSizes(g.Flocks.Histogram()))
+	}
+	ebitenutil.DebugPrint(screen, status)
+}
+
+// flockSizes formats a FlockTracker.Histogram as "1:4 2-3:2 8-15:1".
+func flockSizes(hist []int) string {
+	var parts []string
+	for k, n := range hist {
+		if n == 0 {
+			continue
+		}
+		lo, hi := 1<<k, 1<<(k+1)-1
+		if lo == hi {
+			parts = append(parts, fmt.Sprintf("%d:%d", lo, n))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d:%d", lo, hi, n))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// reloadConfig applies fields edited in the config file since the last
+// poll on top of the running values, so slider tweaks to other fields
+// survive, and rebuilds the panel to move its sliders.
+func (g *Game) reloadConfig() {
+	changes, err := g.Watcher.Poll()
+	if err != nil {
+		slog.Warn("config reload rejected", "path", g.Watcher.Path(), "err", err)
+	}
+	next := *g.Cfg
+	config.ApplyChanges(&next, changes)
+	// Saving from the panel also touches the file; skip values we already have.
+	changes = config.Diff(*g.Cfg, next)
+	if len(changes) == 0 {
+		return
+	}
+	msgs := make([]string, len(changes))
+	for i, c := range changes {
+		msgs[i] = c.String()
+	}
+	// The file may be valid on its own but not combined with values
+	// changed in the panel, e.g. an avoidance radius above the detection radius.
+	if err := next.Validate(); err != nil {
+		slog.Warn("config reload rejected", "path", g.Watcher.Path(), "changes", strings.Join(msgs, ", "), "err", err)
+		g.uiErr = err
+		g.buildUI()
+		return
+	}
+	slog.Info("config reloaded", "path", g.Watcher.Path(), "changes", strings.Join(msgs, ", "))
+
+	g.applyConfig(next)
+}
+
+// applyConfig replaces the running parameters and rebuilds the panel so its
+// sliders match.
+func (g *Game) applyConfig(cfg config.Config) {
+	*g.Cfg = cfg
+	// Earlier load and reload errors no longer describe the running values.
+	g.LoadErr, g.uiErr = nil, nil
+	cfg.Apply(g.World)
+	g.buildUI()
+}
+
+// buildUI rebuilds the panel, showing the latest rejected reload or, failing
+// that, the startup load error.
+func (g *Game) buildUI() {
+	err := g.uiErr
+	if err == nil {
+		err = g.LoadErr
+	}
+	g.Ui, g.uiHooks = BuildUI(UIOptions{
+		World:      g.World,
+		Cfg:        g.Cfg,
+		InitialErr: err,
+		Playback:   &g.play,
+		Tools:      &g.Tools,
+		Spawner:    &g.Spawner,
+		Reset:      g.Reset,
+		Scale:      g.uiScale,
+	})
+}
+
+// playbackChanged refreshes the panel's playback controls after a hotkey or
+// the control API changed them.
+func (g *Game) playbackChanged() {
+	if g.uiHooks.PlaybackChanged != nil {
+		g.uiHooks.PlaybackChanged()
+	}
+}
+
+// typing reports whether a text input in the panel has keyboard focus.
+func (g *Game) typing() bool {
+	return g.ShowUI && g.Ui.Container != nil && inputFocused(g.Ui.Container)
+}
+
+// inputFocused reports whether w or any widget inside it is a focused
+// text input. ebitenui keeps the focused widget to itself, so the panel is
+// searched instead; it only has a few dozen widgets.
+func inputFocused(w widget.PreferredSizeLocateableWidget) bool {
+	switch w := w.(type) {
+	case *widget.TextInput:
+		return w.IsFocused()
+	case *widget.Container:
+		for _, child := range w.Children() {
+			if inputFocused(child) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// cursorOverUI reports whether the mouse is over the parameter panel.
+func (g *Game) cursorOverUI() bool {
+	left, ok := g.panelLeft()
+	mx, _ := ebiten.CursorPosition()
+	return ok && mx >= left
+}
+
+// panelLeft returns the left edge of the parameter panel while it is shown.
+func (g *Game) panelLeft() (int, bool) {
+	if !g.ShowUI || g.Ui.Container == nil {
+		return 0, false
+	}
+	children := g.Ui.Container.Children()
+	if len(children) == 0 {
+		return 0, false
+	}
+	return children[0].GetWidget().Rect.Min.X, true
 }
 
 func uiScaleForWidth(width int) float64 {
@@ -60,40 +383,13 @@ func uiScaleForWidth(width int) float64 {
 }
 
 func (g *Game) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
-	if outsideWidth != g.World.Width || outsideHeight != g.World.Height {
-		ratioX := float64(outsideWidth) / float64(g.World.Width)
-		ratioY := float64(outsideHeight) / float64(g.World.Height)
-		g.World.UpdatePositions(ratioX, ratioY)
-		g.World.Width = outsideWidth
-		g.World.Height = outsideHeight
+	if outsideWidth != g.camera.screenW || outsideHeight != g.camera.screenH {
+		slog.Debug("window resized", "width", outsideWidth, "height", outsideHeight, "mode", g.Resize)
+		g.resize(outsideWidth, outsideHeight)
 	}
 	if newScale := uiScaleForWidth(outsideWidth); newScale != g.uiScale {
 		g.uiScale = newScale
-		g.Ui = BuildUI(g.World, g.Cfg, g.uiScale)
+		g.buildUI()
 	}
 	return outsideWidth, outsideHeight
 }
-
-func (g *Game) drawLing(screen *ebiten.Image, ling sim.Ling, texture *ebiten.Image) {
-	angle := math.Atan2(ling.VY, ling.VX)
-	size := ling.Size
-	vertices := make([]ebiten.Vertex, 3)
-	offsets := []float64{0, math.Pi * 3 / 4, -math.Pi * 3 / 4}
-	for i := range 3 {
-		vertices[i] = ebiten.Vertex{
-			DstX:   float32(ling.X + size*math.Cos(angle+offsets[i])),
-			DstY:   float32(ling.Y + size*math.Sin(angle+offsets[i])),
-			ColorR: 1,
-			ColorG: 1,
-			ColorB: 1,
-			ColorA: 1,
-		}
-	}
-
-	screen.DrawTriangles(vertices, []uint16{0, 1, 2}, texture, nil)
-
-	if g.DebugMode {
-		vector.StrokeCircle(screen, float32(ling.X), float32(ling.Y), float32(g.World.DetectionRadius), 1, color.RGBA{80, 80, 80, 80}, true)
-		vector.StrokeCircle(screen, float32(ling.X), float32(ling.Y), float32(g.World.AvoidanceRadius), 1, color.RGBA{0, 180, 0, 80}, true)
-	}
-}
@@ -0,0 +1,53 @@
+package render
+
+import "fmt"
+
+// ResizeMode controls what a window resize does to the world.
+type ResizeMode int
+
+const (
+	// ResizeLetterbox keeps the world as is and fits it in the window.
+	ResizeLetterbox ResizeMode = iota
+	// ResizeStretch resizes the world to the window and scales positions
+	// with it, which distorts flocks when the aspect ratio changes.
+	ResizeStretch
+	// ResizeExpand resizes the world to the window without moving lings,
+	// clamping any left outside.
+	ResizeExpand
+)
+
+var resizeModeNames = []string{"letterbox", "stretch", "expand"}
+
+func (m ResizeMode) String() string {
+	if int(m) < len(resizeModeNames) {
+		return resizeModeNames[m]
+	}
+	return fmt.Sprintf("ResizeMode(%d)", int(m))
+}
+
+func ParseResizeMode(s string) (ResizeMode, error) {
+	for i, name := range resizeModeNames {
+		if s == name {
+			return ResizeMode(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown resize mode %q, want letterbox, stretch or expand", s)
+}
+
+// resize applies the mode for a window of width x height pixels. The view
+// is refit on the first layout and while the user hasn't panned or zoomed
+// away from the fitted view; otherwise it is kept, unless the world ended
+// up entirely off screen.
+func (g *Game) resize(width, height int) {
+	refit := g.camera.Zoom == 0 || g.camera.fitted(g.World.Width, g.World.Height)
+	g.camera.screenW, g.camera.screenH = width, height
+	switch g.Resize {
+	case ResizeStretch:
+		g.World.Resize(width, height, true)
+	case ResizeExpand:
+		g.World.Resize(width, height, false)
+	}
+	if refit || !g.camera.sees(g.World.Width, g.World.Height) {
+		g.camera.fit(g.World.Width, g.World.Height)
+	}
+}
@@ -0,0 +1,118 @@
+package render
+
+import (
+	"image/color"
+	"math"
+	"slices"
+	"strconv"
+	"swarmlings/config"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// densityFull is the neighbor count at the hot end of the density ramp.
+const densityFull = 30
+
+// rampColor maps t in [0, 1] from blue through green and yellow to red.
+func rampColor(t float64) (r, g, b float32) {
+	t = math.Max(0, math.Min(1, t))
+	return hsvToRGB(240*(1-t), 0.8, 1)
+}
+
+// headingColor puts the direction of travel on the hue wheel, with 0
+// degrees (red) pointing right.
+func headingColor(vx, vy float64) (r, g, b float32) {
+	return hsvToRGB(math.Atan2(vy, vx)*180/math.Pi, 0.7, 1)
+}
+
+// setColorScheme switches schemes from a hotkey. Only the panel's picker
+// needs to follow, so the panel isn't rebuilt.
+func (g *Game) setColorScheme(name string) {
+	g.Cfg.ColorScheme = name
+	if g.uiHooks.ShowScheme != nil {
+		g.uiHooks.ShowScheme(name)
+	}
+}
+
+// cycleColorScheme moves to the next scheme in config.ColorSchemes.
+func (g *Game) cycleColorScheme() {
+	i := slices.Index(config.ColorSchemes, g.Cfg.ColorScheme)
+	g.setColorScheme(config.ColorSchemes[(i+1)%len(config.ColorSchemes)])
+}
+
+// tint returns the color of ling i under the current scheme, or nil when
+// lings are plain white.
+func (g *Game) tint() func(i int) (r, gr, b float32) {
+	lings := g.World.Lings
+	switch g.Cfg.ColorScheme {
+	case "heading":
+		return func(i int) (float32, float32, float32) { return headingColor(lings[i].VX, lings[i].VY) }
+	case "speed":
+		top := math.Max(g.World.MaxSpeed, 1e-9)
+		return func(i int) (float32, float32, float32) {
+			return rampColor(math.Hypot(lings[i].VX, lings[i].VY) / top)
+		}
+	case "density":
+		g.density = g.World.NeighborCounts(g.density)
+		return func(i int) (float32, float32, float32) {
+			return rampColor(float64(g.density[i]) / densityFull)
+		}
+	case "flock":
+		if g.Flocks != nil && len(g.Flocks.IDs) == len(lings) {
+			return func(i int) (float32, float32, float32) { return flockColor(g.Flocks.IDs[i]) }
+		}
+	}
+	return nil
+}
+
+// drawLegend explains the current scheme in the bottom-left corner.
+func (g *Game) drawLegend(screen *ebiten.Image) {
+	// labels are spread evenly under the bar.
+	var labels []string
+	var ramp func(t float64) (r, gr, b float32)
+	switch g.Cfg.ColorScheme {
+	case "heading":
+		labels = []string{"right", "down", "left", "up", "right"}
+		ramp = func(t float64) (float32, float32, float32) { return hsvToRGB(360*t, 0.7, 1) }
+	case "speed":
+		labels = []string{"0", strconv.FormatFloat(g.World.MaxSpeed, 'g', 3, 64)}
+		ramp = rampColor
+	case "density":
+		labels = []string{"0", strconv.Itoa(densityFull) + "+ nearby"}
+		ramp = rampColor
+	case "flock":
+		labels = []string{"one hue per flock"}
+	default:
+		return
+	}
+
+	const barW, barH, pad = 200, 10, 8
+	left := float32(10)
+	top := float32(screen.Bounds().Dy()) - 64
+	vector.FillRect(screen, left, top, barW+2*pad, 54, color.NRGBA{20, 20, 24, 220}, false)
+	ebitenutil.DebugPrintAt(screen, "Color: "+g.Cfg.ColorScheme, int(left+pad), int(top+2))
+	if ramp != nil {
+		const steps = 50
+		for i := range steps {
+			r, gr, b := ramp((float64(i) + 0.5) / steps)
+			clr := color.NRGBA{uint8(r * 255), uint8(gr * 255), uint8(b * 255), 255}
+			vector.FillRect(screen, left+pad+float32(i)*barW/steps, top+20, barW/steps+1, barH, clr, false)
+		}
+	}
+	// The debug font is 6 pixels per character; center each label on its
+	// position, keeping the first and last inside the bar.
+	for i, label := range labels {
+		x := left + pad
+		if len(labels) > 1 {
+			x += float32(i) * barW / float32(len(labels)-1)
+		}
+		w := float32(len(label) * 6)
+		x = max(left+pad, min(x-w/2, left+pad+barW-w))
+		if len(labels) == 1 {
+			x = left + pad
+		}
+		ebitenutil.DebugPrintAt(screen, label, int(x), int(top+32))
+	}
+}
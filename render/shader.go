@@ -0,0 +1,127 @@
+package render
+
+import (
+	_ "embed"
+	"log/slog"
+	"math"
+	"swarmlings/sim"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+//go:embed glow.kage
+var glowSource []byte
+
+const (
+	// maxQuads keeps every vertex of one call addressable by a uint16.
+	maxQuads = (math.MaxUint16 + 1) / 4
+	// glowScale is the halo radius as a multiple of ling size.
+	glowScale = 3
+	// trailDecay is how much of the previous frame survives each frame.
+	trailDecay = 0.82
+)
+
+// glowRenderer draws lings as additive glowing sprites into an accumulation
+// buffer that fades a little each frame, leaving motion-blur trails.
+type glowRenderer struct {
+	shader *ebiten.Shader
+	// failed is set when the shader doesn't compile, so it is tried once.
+	failed bool
+	// accum and prev are swapped each frame.
+	accum, prev *ebiten.Image
+	vertices    []ebiten.Vertex
+	indices     []uint16
+}
+
+// ready compiles the shader on first use and reports whether it can draw.
+func (r *glowRenderer) ready() bool {
+	if r.shader == nil && !r.failed {
+		s, err := ebiten.NewShader(glowSource)
+		if err != nil {
+			slog.Error("glow shader failed to compile, using plain rendering", "err", err)
+			r.failed = true
+			return false
+		}
+		r.shader = s
+	}
+	return r.shader != nil
+}
+
+// reset drops the trails, e.g. when switching back to this renderer.
+func (r *glowRenderer) reset() {
+	if r.accum != nil {
+		r.accum.Clear()
+		r.prev.Clear()
+	}
+}
+
+func (r *glowRenderer) build(lings []sim.Ling, cam *Camera, tint func(i int) (r, g, b float32)) {
+	n := len(lings) * 4
+	if cap(r.vertices) < n {
+		r.vertices = make([]ebiten.Vertex, n)
+	}
+	r.vertices = r.vertices[:n]
+	if m := min(len(lings), maxQuads) * 6; len(r.indices) < m {
+		r.indices = quadIndices(m / 6)
+	}
+	corners := [4][2]float32{{-1, -1}, {1, -1}, {-1, 1}, {1, 1}}
+	for i, l := range lings {
+		var cr, cg, cb float32 = 1, 1, 1
+		if tint != nil {
+			cr, cg, cb = tint(i)
+		}
+		cx, cy := cam.toScreen(l.X, l.Y)
+		half := float32(l.Size * glowScale * cam.Zoom)
+		v := r.vertices[i*4 : i*4+4]
+		for j, c := range corners {
+			v[j] = ebiten.Vertex{
+				DstX:   cx + c[0]*half,
+				DstY:   cy + c[1]*half,
+				SrcX:   c[0],
+				SrcY:   c[1],
+				ColorR: cr,
+				ColorG: cg,
+				ColorB: cb,
+				ColorA: 1,
+			}
+		}
+	}
+}
+
+func (r *glowRenderer) draw(screen *ebiten.Image, lings []sim.Ling, cam *Camera, tint func(i int) (r, g, b float32)) {
+	w, h := screen.Bounds().Dx(), screen.Bounds().Dy()
+	if r.accum == nil || r.accum.Bounds().Dx() != w || r.accum.Bounds().Dy() != h {
+		if r.accum != nil {
+			r.accum.Deallocate()
+			r.prev.Deallocate()
+		}
+		r.accum = ebiten.NewImage(w, h)
+		r.prev = ebiten.NewImage(w, h)
+	}
+	r.accum, r.prev = r.prev, r.accum
+
+	// Fade the previous frame into the fresh buffer, then add this frame.
+	r.accum.Clear()
+	fade := &ebiten.DrawImageOptions{}
+	fade.ColorScale.Scale(trailDecay, trailDecay, trailDecay, trailDecay)
+	r.accum.DrawImage(r.prev, fade)
+
+	r.build(lings, cam, tint)
+	op := &ebiten.DrawTrianglesShaderOptions{Blend: ebiten.BlendLighter}
+	for start := 0; start < len(r.vertices); start += maxQuads * 4 {
+		end := min(start+maxQuads*4, len(r.vertices))
+		r.accum.DrawTrianglesShader(r.vertices[start:end], r.indices[:(end-start)/4*6], r.shader, op)
+	}
+	screen.DrawImage(r.accum, &ebiten.DrawImageOptions{Blend: ebiten.BlendLighter})
+}
+
+// quadIndices returns two triangles for each of n quads whose corners are
+// laid out top-left, top-right, bottom-left, bottom-right.
+func quadIndices(n int) []uint16 {
+	indices := make([]uint16, n*6)
+	for q := range n {
+		base := uint16(q * 4)
+		copy(indices[q*6:], []uint16{base, base + 1, base + 2, base + 1, base + 3, base + 2})
+	}
+	return indices
+}
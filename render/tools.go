@@ -0,0 +1,76 @@
+package render
+
+import (
+	"image/color"
+	"math/rand"
+	"swarmlings/sim"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// selectTool is the palette entry that leaves left clicks to the inspector.
+const selectTool = "select"
+
+// Tools is the mouse tool chosen in the panel: selectTool or the name of a
+// sim.Tool. Radius is in world units; Strength is lings per frame for spawn
+// and velocity change per frame for attract and scatter.
+type Tools struct {
+	Name     string
+	Radius   float64
+	Strength float64
+}
+
+// DefaultTools selects lings with the brush settings the panel starts at.
+func DefaultTools() Tools {
+	return Tools{Name: selectTool, Radius: 40, Strength: 1}
+}
+
+// brush returns the sim tool for the current selection, if it is a brush.
+func (t Tools) brush() (sim.Tool, bool) {
+	for _, tool := range sim.Tools {
+		if tool.String() == t.Name {
+			return tool, true
+		}
+	}
+	return 0, false
+}
+
+// paletteEntries lists the tool picker: select, then every sim brush.
+func paletteEntries() []any {
+	entries := []any{selectTool}
+	for _, tool := range sim.Tools {
+		entries = append(entries, tool.String())
+	}
+	return entries
+}
+
+// applyTool applies the brush under the cursor every frame the left button
+// is held outside the panel.
+func (g *Game) applyTool(overUI bool) {
+	tool, ok := g.Tools.brush()
+	if !ok || overUI || !ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		return
+	}
+	x, y := g.camera.toWorld(ebiten.CursorPosition())
+	before := len(g.World.Lings)
+	g.World.Apply(sim.Stroke{Tool: tool, X: x, Y: y, Radius: g.Tools.Radius, Strength: g.Tools.Strength, Seed: rand.Int63()})
+	if len(g.World.Lings) != before {
+		// The trails no longer line up with the lings until the next tick
+		// records again, which may be frames away while paused.
+		g.World.Trails.Reset()
+	}
+	if tool == sim.ToolErase {
+		// Erasing shifts indices, so the selection may now be another ling.
+		g.deselect()
+	}
+}
+
+// drawBrush outlines the brush at the cursor.
+func (g *Game) drawBrush(screen *ebiten.Image) {
+	if _, ok := g.Tools.brush(); !ok || g.cursorOverUI() {
+		return
+	}
+	mx, my := ebiten.CursorPosition()
+	vector.StrokeCircle(screen, float32(mx), float32(my), float32(g.Tools.Radius*g.camera.Zoom), 1, color.RGBA{200, 200, 210, 160}, true)
+}
@@ -0,0 +1,31 @@
+package render
+
+import (
+	"swarmlings/sim"
+)
+
+// trailWidth is the width of a trail's newest segment in screen pixels.
+const trailWidth = 1.5
+
+// buildTrails adds the trail of every sample-th ling to lines. Older
+// segments are thinner and more transparent. Only the first population
+// trails are drawn, since tint can't color lings that have since been removed.
+func buildTrails(lines *lineBatch, trails *sim.Trails, population int, cam *Camera, sample int, tint func(i int) (r, g, b float32)) {
+	n := trails.Len()
+	if n < 2 {
+		return
+	}
+	for i := 0; i < min(trails.Tracked(), population); i += max(sample, 1) {
+		var cr, cg, cb float32 = 1, 1, 1
+		if tint != nil {
+			cr, cg, cb = tint(i)
+		}
+		sx0, sy0 := cam.toScreen(trails.Point(i, 0))
+		for age := 1; age < n; age++ {
+			sx1, sy1 := cam.toScreen(trails.Point(i, age))
+			fade := 1 - float32(age)/float32(n)
+			lines.add(sx0, sy0, sx1, sy1, trailWidth*fade, cr, cg, cb, 0.6*fade)
+			sx0, sy0 = sx1, sy1
+		}
+	}
+}
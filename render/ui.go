@@ -4,10 +4,15 @@ import (
 	"swarmlings/config"
 	"swarmlings/sim"
 	"bytes"
+	"errors"
 	"fmt"
+	stdimage "image"
 	"image/color"
+	"log/slog"
 	"math"
+	"slices"
 	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/ebitenui/ebitenui"
@@ -18,6 +23,9 @@ import (
 	"golang.org/x/image/font/gofont/goregular"
 )
 
+// noPreset is the picker entry shown when the sliders don't match a preset.
+const noPreset = "(custom)"
+
 var (
 	fontOnce   sync.Once
 	regularSrc *text.GoTextFaceSource
@@ -38,8 +46,31 @@ func initFonts() {
 	})
 }
 
-func BuildUI(world *sim.World, cfg *config.Config, scale float64) ebitenui.UI {
+// UIOptions is what the panel edits and how. The pointers are shared with
+// the game, so edits apply to it directly.
+type UIOptions struct {
+	World *sim.World
+	Cfg   *config.Config
+	// InitialErr is shown until the first edit; nil shows Cfg's own errors.
+	InitialErr error
+	Playback   *Playback
+	Tools      *Tools
+	Spawner    *sim.Spawner
+	Reset      func()
+	Scale      float64
+}
+
+// UIHooks bring a built panel up to date after changes made outside it, by
+// hotkeys or the control API.
+type UIHooks struct {
+	PlaybackChanged func()
+	ShowScheme      func(name string)
+}
+
+func BuildUI(opts UIOptions) (ebitenui.UI, UIHooks) {
 	initFonts()
+	world, cfg, initialErr, play, tools, spawner, scale := opts.World, opts.Cfg, opts.InitialErr, opts.Playback, opts.Tools, opts.Spawner, opts.Scale
+	var hooks UIHooks
 
 	s := func(base int) int { return int(math.Round(float64(base) * scale)) }
 	sf := func(base float64) float64 { return base * scale }
@@ -62,6 +93,7 @@ func BuildUI(world *sim.World, cfg *config.Config, scale float64) ebitenui.UI {
 	trackColor := color.NRGBA{45, 45, 55, 255}
 	inputBG := color.NRGBA{12, 12, 16, 255}
 	inputBorder := color.NRGBA{50, 50, 60, 255}
+	errorColor := color.NRGBA{235, 90, 90, 255}
 	sliderTrack := &widget.SliderTrackImage{
 		Idle:  image.NewNineSliceColor(trackColor),
 		Hover: image.NewNineSliceColor(color.NRGBA{55, 55, 65, 255}),
@@ -106,13 +138,87 @@ func BuildUI(world *sim.World, cfg *config.Config, scale float64) ebitenui.UI {
 		return c
 	}
 
+	errorText := widget.NewText(
+		widget.TextOpts.Text("", &labelFace, errorColor),
+		widget.TextOpts.MaxWidth(float64(s(260))),
+		widget.TextOpts.WidgetOpts(
+			widget.WidgetOpts.LayoutData(widget.RowLayoutData{Stretch: true}),
+		),
+	)
+	// presetsErr is why presets.json couldn't be fully loaded. It stays below
+	// whatever else is shown until the file is saved again.
+	var presetsErr error
+	// showErrors replaces the error text with err, one field error per line.
+	showErrors := func(err error) {
+		var msgs []string
+		for _, err := range []error{err, presetsErr} {
+			var verrs config.ValidationErrors
+			switch {
+			case err == nil:
+			case errors.As(err, &verrs):
+				for _, fe := range verrs {
+					msgs = append(msgs, fe.Error())
+				}
+			default:
+				msgs = append(msgs, err.Error())
+			}
+		}
+		errorText.Label = strings.Join(msgs, "\n")
+	}
+
+	// setField stores val in the config and rejects it if that introduces
+	// validation errors that weren't already present.
+	setField := func(cfgField *float64, val float64) bool {
+		before := cfg.Validate()
+		old := *cfgField
+		*cfgField = val
+		after := cfg.Validate()
+		if introducesErrors(before, after) {
+			*cfgField = old
+			showErrors(after)
+			return false
+		}
+		showErrors(after)
+		return true
+	}
+
+	// refreshers re-read every row's value from cfg after it changes
+	// outside the panel (presets, reloads).
+	var refreshers []func()
+	applyConfig := func(c config.Config) {
+		*cfg = c
+		c.Apply(world)
+		for _, refresh := range refreshers {
+			refresh()
+		}
+		showErrors(cfg.Validate())
+	}
+
+	makeButton := func(label string, minWidth int, onClick func()) *widget.Button {
+		return widget.NewButton(
+			widget.ButtonOpts.WidgetOpts(widget.WidgetOpts.MinSize(s(minWidth), s(28))),
+			widget.ButtonOpts.Image(&widget.ButtonImage{
+				Idle:    image.NewNineSliceColor(accentTeal),
+				Hover:   image.NewNineSliceColor(accentHover),
+				Pressed: image.NewNineSliceColor(accentPressed),
+			}),
+			widget.ButtonOpts.Text(label, &labelFace, &widget.ButtonTextColor{
+				Idle: color.NRGBA{0, 0, 0, 255},
+			}),
+			widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+				onClick()
+			}),
+		)
+	}
+
 	makeRow := func(label string, minVal, maxVal float64, cfgField *float64, formatStr string, apply func(float64)) *widget.Container {
 		var input *widget.TextInput
 		var slider *widget.Slider
 		updating := false
 
 		valToSlider := func(v float64) int {
-			return int((v - minVal) / (maxVal - minVal) * 100)
+			pos := int(math.Round((v - minVal) / (maxVal - minVal) * 100))
+			return max(0, min(100, pos))
 		}
 		sliderToVal := func(s int) float64 {
 			return minVal + (maxVal-minVal)*float64(s)/100.0
@@ -132,17 +238,13 @@ func BuildUI(world *sim.World, cfg *config.Config, scale float64) ebitenui.UI {
 				}
 				updating = true
 				val, err := strconv.ParseFloat(input.GetText(), 64)
-				if err == nil {
-					if val < minVal {
-						val = minVal
-					} else if val > maxVal {
-						val = maxVal
-					}
+				if err != nil {
+					showErrors(fmt.Errorf("%s: %q is not a number", label, input.GetText()))
+				} else if setField(cfgField, val) {
 					apply(val)
-					*cfgField = val
 					slider.Current = valToSlider(val)
-					input.SetText(fmt.Sprintf(formatStr, val))
 				}
+				input.SetText(fmt.Sprintf(formatStr, *cfgField))
 				updating = false
 			}),
 		)
@@ -158,18 +260,30 @@ func BuildUI(world *sim.World, cfg *config.Config, scale float64) ebitenui.UI {
 			widget.SliderOpts.TrackOffset(0),
 			widget.SliderOpts.PageSizeFunc(func() int { return 1 }),
 			widget.SliderOpts.ChangedHandler(func(args *widget.SliderChangedEventArgs) {
-				if updating {
+				// Programmatic moves fire this a frame later; skip them so the
+				// exact value isn't replaced by the slider's coarser step.
+				if updating || args.Current == valToSlider(*cfgField) {
 					return
 				}
 				updating = true
 				val := sliderToVal(args.Current)
-				apply(val)
-				*cfgField = val
-				input.SetText(fmt.Sprintf(formatStr, val))
+				if setField(cfgField, val) {
+					apply(val)
+					input.SetText(fmt.Sprintf(formatStr, val))
+				} else {
+					slider.Current = valToSlider(*cfgField)
+				}
 				updating = false
 			}),
 		)
 
+		refreshers = append(refreshers, func() {
+			updating = true
+			input.SetText(fmt.Sprintf(formatStr, *cfgField))
+			slider.Current = valToSlider(*cfgField)
+			updating = false
+		})
+
 		labelContainer := widget.NewContainer(
 			widget.ContainerOpts.Layout(widget.NewRowLayout(
 				widget.RowLayoutOpts.Direction(widget.DirectionHorizontal),
@@ -193,6 +307,217 @@ func BuildUI(world *sim.World, cfg *config.Config, scale float64) ebitenui.UI {
 		return row
 	}
 
+	// makeCombo builds a dropdown of string entries.
+	makeCombo := func(entries []any, onSelect func(string)) *widget.ListComboButton {
+		return widget.NewListComboButton(
+			widget.ListComboButtonOpts.Entries(entries),
+			widget.ListComboButtonOpts.MaxContentHeight(s(150)),
+			widget.ListComboButtonOpts.ButtonParams(&widget.ButtonParams{
+				Image: &widget.ButtonImage{
+					Idle:    image.NewBorderedNineSliceColor(inputBG, inputBorder, 1),
+					Hover:   image.NewBorderedNineSliceColor(trackColor, inputBorder, 1),
+					Pressed: image.NewBorderedNineSliceColor(trackColor, accentTeal, 1),
+				},
+				TextPadding: &widget.Insets{Top: s(3), Left: s(6), Right: s(6), Bottom: s(3)},
+				TextColor:   &widget.ButtonTextColor{Idle: textPrimary},
+				TextFace:    &labelFace,
+				MinSize:     &stdimage.Point{X: s(170), Y: s(26)},
+			}),
+			widget.ListComboButtonOpts.ListParams(&widget.ListParams{
+				ScrollContainerImage: &widget.ScrollContainerImage{
+					Idle: image.NewBorderedNineSliceColor(panelBG, panelBorder, 1),
+					Mask: image.NewNineSliceColor(panelBG),
+				},
+				Slider: &widget.SliderParams{
+					TrackImage:  sliderTrack,
+					HandleImage: sliderHandle,
+				},
+				EntryFace: &labelFace,
+				EntryColor: &widget.ListEntryColor{
+					Selected:                  textPrimary,
+					Unselected:                textDim,
+					SelectedBackground:        trackColor,
+					SelectedFocusedBackground: trackColor,
+					FocusedBackground:         inputBG,
+				},
+				EntryTextPadding: &widget.Insets{Top: s(3), Left: s(6), Right: s(6), Bottom: s(3)},
+				MinSize:          &stdimage.Point{X: s(170), Y: 0},
+			}),
+			widget.ListComboButtonOpts.EntryLabelFunc(
+				func(e any) string { return e.(string) },
+				func(e any) string { return e.(string) },
+			),
+			widget.ListComboButtonOpts.EntrySelectedHandler(func(args *widget.ListComboButtonEntrySelectedEventArgs) {
+				onSelect(args.Entry.(string))
+			}),
+		)
+	}
+
+	// Presets live on disk; the picker is rebuilt whenever the list changes.
+	buildPresets := func() *widget.Container {
+		presets, loadErr := config.LoadPresets()
+		// Skipped entries come back as ValidationErrors next to the rest;
+		// any other error means the file couldn't be read at all.
+		var verrs config.ValidationErrors
+		unreadable := loadErr != nil && !errors.As(loadErr, &verrs)
+		presetsErr = loadErr
+		if unreadable {
+			presetsErr = fmt.Errorf("presets: %w", loadErr)
+		}
+		if loadErr != nil {
+			slog.Warn("presets load failed", "err", loadErr)
+		}
+		selected := ""
+
+		pickerHolder := widget.NewContainer(
+			widget.ContainerOpts.Layout(widget.NewRowLayout(
+				widget.RowLayoutOpts.Direction(widget.DirectionHorizontal),
+			)),
+		)
+		var rebuildPicker func()
+		rebuildPicker = func() {
+			entries := []any{noPreset}
+			for _, p := range presets {
+				entries = append(entries, p.Name)
+			}
+			picker := makeCombo(entries, func(name string) {
+				if name == noPreset {
+					selected = ""
+					return
+				}
+				selected = name
+				if c, ok := presets.Get(name); ok {
+					// Presets are about behavior; keep the current colors.
+					c.ColorScheme = cfg.ColorScheme
+					applyConfig(c)
+				}
+			})
+			if selected != "" {
+				picker.SetSelectedEntry(selected)
+			}
+			pickerHolder.RemoveChildren()
+			pickerHolder.AddChild(picker)
+		}
+		rebuildPicker()
+
+		nameInput := widget.NewTextInput(
+			widget.TextInputOpts.WidgetOpts(widget.WidgetOpts.MinSize(s(170), s(24))),
+			widget.TextInputOpts.Image(inputImage),
+			widget.TextInputOpts.Face(&labelFace),
+			widget.TextInputOpts.Color(inputColor),
+			widget.TextInputOpts.Padding(&widget.Insets{Top: s(3), Left: s(4), Right: s(4), Bottom: s(3)}),
+			widget.TextInputOpts.Placeholder("new preset name"),
+		)
+
+		// Saving after a failed read would overwrite the presets that
+		// couldn't be read. Skipped entries are dropped by the save.
+		savePresets := func(next config.Presets) {
+			if unreadable {
+				showErrors(errors.New("presets: fix presets.json before saving"))
+				return
+			}
+			if err := config.SavePresets(next); err != nil {
+				slog.Error("presets save failed", "err", err)
+				showErrors(fmt.Errorf("presets: %w", err))
+				return
+			}
+			presets = next
+			presetsErr = nil
+			showErrors(cfg.Validate())
+			rebuildPicker()
+		}
+		saveAsBtn := makeButton("Save as", 80, func() {
+			name := strings.TrimSpace(nameInput.GetText())
+			if name == "" || name == noPreset {
+				showErrors(errors.New("presets: enter a name to save the current values"))
+				return
+			}
+			if err := cfg.Validate(); err != nil {
+				showErrors(err)
+				return
+			}
+			selected = name
+			savePresets(presets.Set(name, *cfg))
+			nameInput.SetText("")
+		})
+		deleteBtn := makeButton("Delete", 80, func() {
+			if selected == "" {
+				showErrors(errors.New("presets: select a preset to delete"))
+				return
+			}
+			name := selected
+			selected = ""
+			savePresets(presets.Delete(name))
+		})
+
+		makeLine := func(children ...widget.PreferredSizeLocateableWidget) *widget.Container {
+			c := widget.NewContainer(
+				widget.ContainerOpts.Layout(widget.NewRowLayout(
+					widget.RowLayoutOpts.Direction(widget.DirectionHorizontal),
+					widget.RowLayoutOpts.Spacing(s(8)),
+				)),
+				widget.ContainerOpts.WidgetOpts(
+					widget.WidgetOpts.LayoutData(widget.RowLayoutData{Stretch: true}),
+				),
+			)
+			c.AddChild(children...)
+			return c
+		}
+		section := widget.NewContainer(
+			widget.ContainerOpts.Layout(widget.NewRowLayout(
+				widget.RowLayoutOpts.Direction(widget.DirectionVertical),
+				widget.RowLayoutOpts.Spacing(s(6)),
+			)),
+			widget.ContainerOpts.WidgetOpts(
+				widget.WidgetOpts.LayoutData(widget.RowLayoutData{Stretch: true}),
+			),
+		)
+		section.AddChild(makeLine(pickerHolder, deleteBtn))
+		section.AddChild(makeLine(nameInput, saveAsBtn))
+		return section
+	}
+
+	buildPlayback := func() *widget.Container {
+		var pauseBtn *widget.Button
+		pauseLabel := func() string {
+			if play.Paused() {
+				return "Resume"
+			}
+			return "Pause"
+		}
+		speedText := widget.NewText(
+			widget.TextOpts.Text(play.SpeedLabel(), &valueFace, textPrimary),
+			widget.TextOpts.WidgetOpts(widget.WidgetOpts.MinSize(s(44), 0)),
+			widget.TextOpts.Position(widget.TextPositionCenter, widget.TextPositionCenter),
+		)
+		hooks.PlaybackChanged = func() {
+			pauseBtn.Text().Label = pauseLabel()
+			speedText.Label = play.SpeedLabel()
+		}
+		control := func(fn func()) func() {
+			return func() {
+				fn()
+				hooks.PlaybackChanged()
+			}
+		}
+		pauseBtn = makeButton(pauseLabel(), 70, control(play.TogglePause))
+		stepBtn := makeButton("Step", 50, control(play.Step))
+		slowerBtn := makeButton("-", 28, control(play.Slower))
+		fasterBtn := makeButton("+", 28, control(play.Faster))
+
+		c := widget.NewContainer(
+			widget.ContainerOpts.Layout(widget.NewRowLayout(
+				widget.RowLayoutOpts.Direction(widget.DirectionHorizontal),
+				widget.RowLayoutOpts.Spacing(s(8)),
+			)),
+			widget.ContainerOpts.WidgetOpts(
+				widget.WidgetOpts.LayoutData(widget.RowLayoutData{Stretch: true}),
+			),
+		)
+		c.AddChild(pauseBtn, stepBtn, slowerBtn, speedText, fasterBtn)
+		return c
+	}
+
 	rootContainer := widget.NewContainer(
 		widget.ContainerOpts.Layout(widget.NewAnchorLayout()),
 	)
@@ -218,6 +543,70 @@ func BuildUI(world *sim.World, cfg *config.Config, scale float64) ebitenui.UI {
 	panel.AddChild(widget.NewText(widget.TextOpts.Text("Ling Parameters", &titleFace, textPrimary)))
 	panel.AddChild(makeSeparator())
 
+	panel.AddChild(makeHeader("Playback"))
+	panel.AddChild(buildPlayback())
+
+	panel.AddChild(makeSeparator())
+
+	panel.AddChild(makeHeader("Population"))
+	// The slider edits a float; Reset spawns the rounded count. Zero would
+	// mean "keep the current count" to Reset, so the panel stops at one.
+	population := float64(spawner.Population)
+	panel.AddChild(makeRow("Lings", 1, 5000, &population, "%.0f", func(v float64) {
+		spawner.Population = max(1, int(math.Round(v)))
+	}))
+	distributions := make([]any, len(sim.Distributions))
+	for i, d := range sim.Distributions {
+		distributions[i] = string(d)
+	}
+	distributionPicker := makeCombo(distributions, func(name string) {
+		spawner.Distribution = sim.Distribution(name)
+	})
+	distributionPicker.SetSelectedEntry(string(spawner.Distribution))
+	velocities := make([]any, len(sim.VelocityModes))
+	for i, v := range sim.VelocityModes {
+		velocities[i] = string(v)
+	}
+	velocityPicker := makeCombo(velocities, func(name string) {
+		spawner.Velocity = sim.VelocityMode(name)
+	})
+	velocityPicker.SetSelectedEntry(string(spawner.Velocity))
+	panel.AddChild(distributionPicker)
+	panel.AddChild(velocityPicker)
+	panel.AddChild(makeButton("Reset", 120, opts.Reset))
+
+	panel.AddChild(makeSeparator())
+
+	panel.AddChild(makeHeader("Tools"))
+	toolPicker := makeCombo(paletteEntries(), func(name string) {
+		tools.Name = name
+	})
+	toolPicker.SetSelectedEntry(tools.Name)
+	panel.AddChild(toolPicker)
+	panel.AddChild(makeRow("Radius", 5, 200, &tools.Radius, "%.0f", func(float64) {}))
+	panel.AddChild(makeRow("Strength", 0, 10, &tools.Strength, "%.1f", func(float64) {}))
+
+	panel.AddChild(makeSeparator())
+
+	panel.AddChild(makeHeader("Colors"))
+	schemes := make([]any, len(config.ColorSchemes))
+	for i, name := range config.ColorSchemes {
+		schemes[i] = name
+	}
+	schemePicker := makeCombo(schemes, func(name string) {
+		cfg.ColorScheme = name
+	})
+	schemePicker.SetSelectedEntry(cfg.ColorScheme)
+	hooks.ShowScheme = func(name string) { schemePicker.SetSelectedEntry(name) }
+	panel.AddChild(schemePicker)
+
+	panel.AddChild(makeSeparator())
+
+	panel.AddChild(makeHeader("Presets"))
+	panel.AddChild(buildPresets())
+
+	panel.AddChild(makeSeparator())
+
 	panel.AddChild(makeHeader("Forces"))
 	panel.AddChild(makeRow("Avoid", 0, 2.0, &cfg.AvoidanceFactor, "%.3f", func(v float64) {
 		world.AvoidanceFactor = v
@@ -269,26 +658,44 @@ func BuildUI(world *sim.World, cfg *config.Config, scale float64) ebitenui.UI {
 			}),
 		),
 	)
-	saveBtn := widget.NewButton(
-		widget.ButtonOpts.WidgetOpts(widget.WidgetOpts.MinSize(s(120), s(28))),
-		widget.ButtonOpts.Image(&widget.ButtonImage{
-			Idle:    image.NewNineSliceColor(accentTeal),
-			Hover:   image.NewNineSliceColor(accentHover),
-			Pressed: image.NewNineSliceColor(accentPressed),
-		}),
-		widget.ButtonOpts.Text("Save", &labelFace, &widget.ButtonTextColor{
-			Idle: color.NRGBA{0, 0, 0, 255},
-		}),
-		widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
-			config.Save(*cfg)
-		}),
-	)
+	saveBtn := makeButton("Save", 120, func() {
+		if err := cfg.Validate(); err != nil {
+			showErrors(err)
+			return
+		}
+		if err := config.Save(*cfg); err != nil {
+			slog.Error("config save failed", "err", err)
+			showErrors(fmt.Errorf("save: %w", err))
+		}
+	})
 	btnContainer.AddChild(saveBtn)
 	panel.AddChild(btnContainer)
+	panel.AddChild(errorText)
+	// Load and reload errors also cover unknown keys and unparseable files,
+	// which Validate can't see.
+	if initialErr != nil {
+		showErrors(initialErr)
+	} else {
+		showErrors(cfg.Validate())
+	}
 
 	rootContainer.AddChild(panel)
 
 	return ebitenui.UI{
 		Container: rootContainer,
+	}, hooks
+}
+
+// introducesErrors reports whether after contains a field error that
+// before did not.
+func introducesErrors(before, after error) bool {
+	var prev, next config.ValidationErrors
+	errors.As(before, &prev)
+	errors.As(after, &next)
+	for _, fe := range next {
+		if !slices.Contains(prev, fe) {
+			return true
+		}
 	}
+	return false
 }
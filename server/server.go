@@ -0,0 +1,269 @@
+// Package server exposes a running simulation over a local HTTP API.
+//
+// The simulation is owned by the game loop, so handlers never touch it
+// directly: each request is queued and executed by Poll, which the loop
+// calls once per frame.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"swarmlings/config"
+	"swarmlings/sim"
+	"sync/atomic"
+	"time"
+)
+
+// Target is the running simulation. Its methods are only called from Poll.
+type Target interface {
+	Sim() *sim.World
+	Params() config.Config
+	// SetParams applies cfg to the world and the parameter panel.
+	SetParams(cfg config.Config)
+	Paused() bool
+	SetPaused(paused bool)
+	// Step advances a paused simulation by one tick.
+	Step()
+	Reset()
+}
+
+// requestTimeout bounds how long a handler waits for the loop to pick up
+// its request.
+const requestTimeout = 2 * time.Second
+
+type Server struct {
+	target Target
+	reqs   chan func()
+	http   *http.Server
+	ln     net.Listener
+	stream broadcaster
+}
+
+func New(target Target) *Server {
+	s := &Server{target: target, reqs: make(chan func(), 16)}
+	s.http = &http.Server{Handler: s.routes(), ReadHeaderTimeout: 5 * time.Second}
+	return s
+}
+
+// Start listens on addr and serves in the background.
+func (s *Server) Start(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	s.ln = ln
+	go s.http.Serve(ln)
+	return nil
+}
+
+func (s *Server) Addr() net.Addr {
+	return s.ln.Addr()
+}
+
+func (s *Server) Close() error {
+	return s.http.Close()
+}
+
+// Poll runs every queued request against the target. Call it from the
+// goroutine that owns the simulation.
+func (s *Server) Poll() {
+	for {
+		select {
+		case fn := <-s.reqs:
+			fn()
+		default:
+			return
+		}
+	}
+}
+
+// Do runs fn on the simulation's goroutine and waits for it to finish.
+// If ctx ends first, fn is guaranteed not to run, so an error from Do
+// always means nothing was applied.
+func (s *Server) Do(ctx context.Context, fn func(t Target)) error {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	// Whoever moves state off pending first decides: Poll runs fn, or the
+	// caller gives up.
+	const (
+		pending int32 = iota
+		started
+		abandoned
+	)
+	var state atomic.Int32
+	done := make(chan struct{})
+	req := func() {
+		if state.CompareAndSwap(pending, started) {
+			fn(s.target)
+			close(done)
+		}
+	}
+	select {
+	case s.reqs <- req:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		if state.CompareAndSwap(pending, abandoned) {
+			return ctx.Err()
+		}
+		// Poll already started fn; it finishes within the frame.
+		<-done
+		return nil
+	}
+}
+
+type lingState struct {
+	X  float64 `json:"x"`
+	Y  float64 `json:"y"`
+	VX float64 `json:"vx"`
+	VY float64 `json:"vy"`
+}
+
+type status struct {
+	Tick       int  `json:"tick"`
+	Paused     bool `json:"paused"`
+	Population int  `json:"population"`
+	Width      int  `json:"width"`
+	Height     int  `json:"height"`
+}
+
+func (s *Server) routes() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/status", s.handleStatus)
+	mux.HandleFunc("GET /api/metrics", s.handleMetrics)
+	mux.HandleFunc("GET /api/lings", s.handleLings)
+	mux.HandleFunc("GET /api/params", s.handleGetParams)
+	mux.HandleFunc("PUT /api/params", s.handlePutParams)
+	mux.HandleFunc("POST /api/pause", s.control(func(t Target) { t.SetPaused(true) }))
+	mux.HandleFunc("POST /api/resume", s.control(func(t Target) { t.SetPaused(false) }))
+	mux.HandleFunc("POST /api/step", s.control(Target.Step))
+	mux.HandleFunc("POST /api/reset", s.control(Target.Reset))
+	mux.HandleFunc("GET /api/stream", s.handleStream)
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, map[string]any{"error": err.Error()})
+}
+
+// run executes fn on the simulation goroutine and reports a timeout as 503.
+func (s *Server) run(w http.ResponseWriter, r *http.Request, fn func(t Target)) bool {
+	if err := s.Do(r.Context(), fn); err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return false
+	}
+	return true
+}
+
+func statusOf(t Target) status {
+	w := t.Sim()
+	return status{Tick: w.Tick, Paused: t.Paused(), Population: len(w.Lings), Width: w.Width, Height: w.Height}
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	var st status
+	if s.run(w, r, func(t Target) { st = statusOf(t) }) {
+		writeJSON(w, http.StatusOK, st)
+	}
+}
+
+// metricsResponse adds the flocks to the order parameters. FlockSizes is
+// sim.FlockTracker.Histogram: entry k counts flocks of 2^k to 2^(k+1)-1 lings.
+type metricsResponse struct {
+	sim.Metrics
+	Flocks     int   `json:"flocks"`
+	FlockSizes []int `json:"flock_sizes"`
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	var m metricsResponse
+	if s.run(w, r, func(t Target) {
+		flocks := sim.NewFlockTracker(0)
+		flocks.Update(t.Sim())
+		m = metricsResponse{t.Sim().Metrics(), flocks.Count(), flocks.Histogram()}
+	}) {
+		writeJSON(w, http.StatusOK, m)
+	}
+}
+
+func (s *Server) handleLings(w http.ResponseWriter, r *http.Request) {
+	var lings []lingState
+	if s.run(w, r, func(t Target) {
+		lings = make([]lingState, len(t.Sim().Lings))
+		for i, l := range t.Sim().Lings {
+			lings[i] = lingState{l.X, l.Y, l.VX, l.VY}
+		}
+	}) {
+		writeJSON(w, http.StatusOK, lings)
+	}
+}
+
+func (s *Server) handleGetParams(w http.ResponseWriter, r *http.Request) {
+	var cfg config.Config
+	if s.run(w, r, func(t Target) { cfg = t.Params() }) {
+		writeJSON(w, http.StatusOK, cfg)
+	}
+}
+
+// handlePutParams merges a partial JSON object over the current parameters.
+// Unknown keys and invalid values are rejected with the field errors and
+// nothing is applied.
+func (s *Server) handlePutParams(w http.ResponseWriter, r *http.Request) {
+	var patch map[string]float64
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16)).Decode(&patch); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	var cfg config.Config
+	var applyErr error
+	ok := s.run(w, r, func(t Target) {
+		cfg = t.Params()
+		var errs config.ValidationErrors
+		for field, v := range patch {
+			if err := cfg.Set(field, v); err != nil {
+				errs = append(errs, config.FieldError{Field: field, Message: err.Error()})
+			}
+		}
+		// Report invalid values alongside unknown keys.
+		var verrs config.ValidationErrors
+		if errors.As(cfg.Validate(), &verrs) {
+			errs = append(errs, verrs...)
+		}
+		if len(errs) > 0 {
+			applyErr = errs
+			return
+		}
+		t.SetParams(cfg)
+	})
+	if !ok {
+		return
+	}
+	var verrs config.ValidationErrors
+	if errors.As(applyErr, &verrs) {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]any{"errors": verrs})
+		return
+	}
+	writeJSON(w, http.StatusOK, cfg)
+}
+
+func (s *Server) control(fn func(t Target)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var st status
+		if s.run(w, r, func(t Target) { fn(t); st = statusOf(t) }) {
+			writeJSON(w, http.StatusOK, st)
+		}
+	}
+}
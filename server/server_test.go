@@ -0,0 +1,171 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"swarmlings/config"
+	"swarmlings/sim"
+	"testing"
+	"time"
+)
+
+type fakeTarget struct {
+	world  sim.World
+	cfg    config.Config
+	paused bool
+	steps  int
+	resets int
+}
+
+func (f *fakeTarget) Sim() *sim.World       { return &f.world }
+func (f *fakeTarget) Params() config.Config { return f.cfg }
+func (f *fakeTarget) SetParams(cfg config.Config) {
+	f.cfg = cfg
+	cfg.Apply(&f.world)
+}
+func (f *fakeTarget) Paused() bool          { return f.paused }
+func (f *fakeTarget) SetPaused(paused bool) { f.paused = paused }
+func (f *fakeTarget) Step()                 { f.steps++ }
+func (f *fakeTarget) Reset()                { f.resets++ }
+
+// newTestServer serves s over httptest and polls it like a game loop would.
+func newTestServer(t *testing.T) (*fakeTarget, *Server, *httptest.Server) {
+	t.Helper()
+	target := &fakeTarget{
+		world: sim.New([]sim.Ling{{X: 10, Y: 20, VX: 1}, {X: 30, Y: 40, VY: -1}}, 100, 100),
+		cfg:   config.Default(),
+	}
+	s := New(target)
+	ts := httptest.NewServer(s.http.Handler)
+	stop := make(chan struct{})
+	go func() {
+		tick := time.NewTicker(time.Millisecond)
+		defer tick.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-tick.C:
+				s.Poll()
+			}
+		}
+	}()
+	t.Cleanup(func() {
+		ts.Close()
+		close(stop)
+	})
+	return target, s, ts
+}
+
+func do(t *testing.T, method, url, body string) (*http.Response, map[string]any) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var out map[string]any
+	json.NewDecoder(resp.Body).Decode(&out)
+	return resp, out
+}
+
+func TestGetEndpoints(t *testing.T) {
+	_, _, ts := newTestServer(t)
+
+	resp, body := do(t, http.MethodGet, ts.URL+"/api/metrics", "")
+	if resp.StatusCode != http.StatusOK || body["population"] != 2.0 {
+		t.Errorf("expected metrics for 2 lings, got %d %v", resp.StatusCode, body)
+	}
+	// The two lings are well within the default detection radius.
+	if body["flocks"] != 1.0 || fmt.Sprint(body["flock_sizes"]) != "[0 1]" {
+		t.Errorf("expected one flock of 2, got %v %v", body["flocks"], body["flock_sizes"])
+	}
+
+	resp, err := http.Get(ts.URL + "/api/lings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var lings []lingState
+	if err := json.NewDecoder(resp.Body).Decode(&lings); err != nil {
+		t.Fatal(err)
+	}
+	if len(lings) != 2 || lings[1].X != 30 || lings[1].VY != -1 {
+		t.Errorf("unexpected lings %+v", lings)
+	}
+}
+
+func TestPutParams(t *testing.T) {
+	target, _, ts := newTestServer(t)
+
+	resp, body := do(t, http.MethodPut, ts.URL+"/api/params", `{"max_speed": 5, "wall_force": 0.5}`)
+	if resp.StatusCode != http.StatusOK || body["max_speed"] != 5.0 {
+		t.Fatalf("expected params to be applied, got %d %v", resp.StatusCode, body)
+	}
+	if target.world.MaxSpeed != 5 || target.world.WallForce != 0.5 {
+		t.Errorf("expected world to be updated, got max_speed=%v wall_force=%v", target.world.MaxSpeed, target.world.WallForce)
+	}
+
+	resp, body = do(t, http.MethodPut, ts.URL+"/api/params", `{"max_speed": -1, "bogus": 1}`)
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422, got %d %v", resp.StatusCode, body)
+	}
+	if errs, _ := body["errors"].([]any); len(errs) != 2 {
+		t.Errorf("expected the unknown key and the invalid value to be reported, got %v", body)
+	}
+	if target.cfg.MaxSpeed != 5 {
+		t.Errorf("expected rejected request to change nothing, got max_speed=%v", target.cfg.MaxSpeed)
+	}
+}
+
+func TestControlEndpoints(t *testing.T) {
+	target, _, ts := newTestServer(t)
+
+	if resp, body := do(t, http.MethodPost, ts.URL+"/api/pause", ""); body["paused"] != true {
+		t.Errorf("expected paused status, got %d %v", resp.StatusCode, body)
+	}
+	do(t, http.MethodPost, ts.URL+"/api/step", "")
+	do(t, http.MethodPost, ts.URL+"/api/reset", "")
+	if _, body := do(t, http.MethodPost, ts.URL+"/api/resume", ""); body["paused"] != false {
+		t.Errorf("expected running status, got %v", body)
+	}
+	if target.steps != 1 || target.resets != 1 {
+		t.Errorf("expected one step and one reset, got %d and %d", target.steps, target.resets)
+	}
+	if resp, _ := do(t, http.MethodGet, ts.URL+"/api/pause", ""); resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("expected GET on a control endpoint to be rejected, got %d", resp.StatusCode)
+	}
+}
+
+func TestDoTimesOutWithoutPoll(t *testing.T) {
+	s := New(&fakeTarget{})
+	s.reqs = make(chan func())
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := s.Do(ctx, func(Target) {}); err == nil {
+		t.Error("expected an error when nothing polls the server")
+	}
+}
+
+func TestDoSkipsAbandonedRequest(t *testing.T) {
+	s := New(&fakeTarget{})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	ran := false
+	if err := s.Do(ctx, func(Target) { ran = true }); err == nil {
+		t.Fatal("expected an error when nothing polls the server")
+	}
+	// The request is still queued; a late Poll must not apply it.
+	s.Poll()
+	if ran {
+		t.Error("expected a timed-out request not to run")
+	}
+}
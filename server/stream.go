@@ -0,0 +1,226 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"swarmlings/sim"
+	"sync"
+)
+
+// frame is an immutable copy of the world for one tick, shared by every
+// subscriber.
+type frame struct {
+	tick          int
+	width, height int
+	lings         []sim.Ling
+}
+
+// subscriber holds at most one pending frame. Publish overwrites it, so a
+// client that can't keep up skips ticks instead of holding up the game loop.
+type subscriber struct {
+	frames chan *frame
+}
+
+type broadcaster struct {
+	mu       sync.Mutex
+	subs     map[*subscriber]struct{}
+	lastTick int
+}
+
+func (b *broadcaster) subscribe() *subscriber {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.subs == nil {
+		b.subs = make(map[*subscriber]struct{})
+	}
+	sub := &subscriber{frames: make(chan *frame, 1)}
+	b.subs[sub] = struct{}{}
+	return sub
+}
+
+func (b *broadcaster) unsubscribe(sub *subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.subs, sub)
+}
+
+// Publish hands the world's current tick to every stream subscriber without
+// blocking. It does nothing when no one is subscribed or the tick was
+// already published, so the game loop can call it every frame.
+func (s *Server) Publish(w *sim.World) {
+	b := &s.stream
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.subs) == 0 || w.Tick == b.lastTick {
+		return
+	}
+	b.lastTick = w.Tick
+	f := &frame{tick: w.Tick, width: w.Width, height: w.Height, lings: append([]sim.Ling(nil), w.Lings...)}
+	for sub := range b.subs {
+		// Drop the stale frame if the client hasn't taken it yet.
+		select {
+		case <-sub.frames:
+		default:
+		}
+		sub.frames <- f
+	}
+}
+
+// streamOptions are parsed from the stream URL's query string.
+type streamOptions struct {
+	// every sends at most one tick in every N.
+	every int
+	// sample sends one ling in every N.
+	sample int
+	// roi, if set, keeps only lings inside [x0, x1) x [y0, y1).
+	roi                  bool
+	x0, y0, x1, y1       float64
+	velocity, includeIDs bool
+}
+
+func parseStreamOptions(r *http.Request) (streamOptions, error) {
+	q := r.URL.Query()
+	opts := streamOptions{every: 1, sample: 1}
+	positive := func(key string, dst *int) error {
+		if v := q.Get(key); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				return fmt.Errorf("%s must be a positive integer", key)
+			}
+			*dst = n
+		}
+		return nil
+	}
+	if err := positive("every", &opts.every); err != nil {
+		return opts, err
+	}
+	if err := positive("sample", &opts.sample); err != nil {
+		return opts, err
+	}
+	if v := q.Get("roi"); v != "" {
+		parts := strings.Split(v, ",")
+		if len(parts) != 4 {
+			return opts, errors.New("roi must be x0,y0,x1,y1")
+		}
+		var vals [4]float64
+		for i, p := range parts {
+			f, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+			if err != nil {
+				return opts, errors.New("roi must be x0,y0,x1,y1")
+			}
+			vals[i] = f
+		}
+		opts.roi = true
+		opts.x0, opts.y0 = math.Min(vals[0], vals[2]), math.Min(vals[1], vals[3])
+		opts.x1, opts.y1 = math.Max(vals[0], vals[2]), math.Max(vals[1], vals[3])
+	}
+	opts.velocity = q.Get("vel") == "1"
+	opts.includeIDs = q.Get("ids") == "1"
+	return opts, nil
+}
+
+// throttle passes at most one tick in every n, counted from the last tick
+// it passed, so ticks the subscriber buffer dropped don't shift the
+// schedule. A tick earlier than the last one (after a reset) always passes.
+type throttle struct {
+	every   int
+	last    int
+	started bool
+}
+
+func (t *throttle) pass(tick int) bool {
+	if t.started && tick > t.last && tick-t.last < t.every {
+		return false
+	}
+	t.last, t.started = tick, true
+	return true
+}
+
+// streamFrame is one SSE event. D holds Stride numbers per ling: x, y and,
+// when requested, vx, vy, rounded to 0.1.
+type streamFrame struct {
+	Tick   int       `json:"tick"`
+	Width  int       `json:"w"`
+	Height int       `json:"h"`
+	Stride int       `json:"stride"`
+	D      []float64 `json:"d"`
+	IDs    []int     `json:"ids,omitempty"`
+}
+
+func (o streamOptions) encode(f *frame, out *streamFrame) {
+	out.Tick, out.Width, out.Height = f.tick, f.width, f.height
+	out.Stride = 2
+	if o.velocity {
+		out.Stride = 4
+	}
+	out.D = out.D[:0]
+	out.IDs = out.IDs[:0]
+	round := func(v float64) float64 { return math.Round(v*10) / 10 }
+	for i := 0; i < len(f.lings); i += o.sample {
+		l := f.lings[i]
+		if o.roi && (l.X < o.x0 || l.X >= o.x1 || l.Y < o.y0 || l.Y >= o.y1) {
+			continue
+		}
+		out.D = append(out.D, round(l.X), round(l.Y))
+		if o.velocity {
+			out.D = append(out.D, round(l.VX), round(l.VY))
+		}
+		if o.includeIDs {
+			out.IDs = append(out.IDs, i)
+		}
+	}
+}
+
+// handleStream serves ling positions as Server-Sent Events, one event per
+// published tick (subject to every=N).
+func (s *Server) handleStream(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseStreamOptions(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, errors.New("streaming unsupported"))
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	sub := s.stream.subscribe()
+	defer s.stream.unsubscribe(sub)
+
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	var out streamFrame
+	due := throttle{every: opts.every}
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case f := <-sub.frames:
+			if !due.pass(f.tick) {
+				continue
+			}
+			opts.encode(f, &out)
+			bw.WriteString("event: frame\ndata: ")
+			// Encode ends with a newline; the blank line closes the event.
+			if err := enc.Encode(&out); err != nil {
+				return
+			}
+			bw.WriteString("\n")
+			if err := bw.Flush(); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
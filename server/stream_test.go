@@ -0,0 +1,163 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"swarmlings/sim"
+	"testing"
+	"time"
+)
+
+func TestPublishDoesNotBlockOnSlowClients(t *testing.T) {
+	s := New(&fakeTarget{})
+	s.stream.subscribe() // never reads
+	world := sim.New([]sim.Ling{{X: 1, Y: 1}}, 10, 10)
+
+	done := make(chan struct{})
+	go func() {
+		for range 1000 {
+			world.Tick++
+			s.Publish(&world)
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Publish blocked on a subscriber that never reads")
+	}
+}
+
+func TestStreamFiltersFrames(t *testing.T) {
+	target, s, ts := newTestServer(t)
+	target.world.Lings = []sim.Ling{
+		{X: 10, Y: 10, VX: 1.04},
+		{X: 20, Y: 10},
+		{X: 80, Y: 80}, // outside the roi
+		{X: 30, Y: 30},
+	}
+
+	resp, err := http.Get(ts.URL + "/api/stream?roi=0,0,50,50&sample=2&every=2&vel=1&ids=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected an event stream, got %q", ct)
+	}
+
+	// Keep publishing until the subscription registers and two frames get
+	// through.
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			case <-time.After(time.Millisecond):
+				target.world.Tick++
+				s.Publish(&target.world)
+			}
+		}
+	}()
+
+	sc := bufio.NewScanner(resp.Body)
+	prev := -1
+	for sc.Scan() {
+		line, ok := strings.CutPrefix(sc.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var f streamFrame
+		if err := json.Unmarshal([]byte(line), &f); err != nil {
+			t.Fatal(err)
+		}
+		if prev >= 0 && f.Tick-prev < 2 {
+			t.Errorf("expected ticks at least 2 apart, got %d after %d", f.Tick, prev)
+		}
+		// sample=2 keeps lings 0 and 2, and the roi drops ling 2.
+		if f.Stride != 4 || len(f.IDs) != 1 || f.IDs[0] != 0 {
+			t.Fatalf("unexpected frame %+v", f)
+		}
+		if f.D[0] != 10 || f.D[2] != 1 {
+			t.Errorf("expected rounded x=10 vx=1, got %v", f.D)
+		}
+		if prev >= 0 {
+			return
+		}
+		prev = f.Tick
+	}
+	t.Fatal("stream ended before two frames")
+}
+
+func TestThrottle(t *testing.T) {
+	testCases := []struct {
+		desc  string
+		every int
+		ticks []int
+		want  []int
+	}{
+		{
+			desc:  "every tick",
+			every: 1,
+			ticks: []int{1, 2, 3},
+			want:  []int{1, 2, 3},
+		},
+		{
+			desc:  "consecutive ticks",
+			every: 3,
+			ticks: []int{1, 2, 3, 4, 5, 6, 7},
+			want:  []int{1, 4, 7},
+		},
+		{
+			desc:  "dropped ticks keep the spacing",
+			every: 2,
+			ticks: []int{3, 5, 7, 9},
+			want:  []int{3, 5, 7, 9},
+		},
+		{
+			desc:  "skipped ticks count",
+			every: 4,
+			ticks: []int{1, 3, 6, 9, 10},
+			want:  []int{1, 6, 10},
+		},
+		{
+			desc:  "reset restarts the schedule",
+			every: 5,
+			ticks: []int{10, 12, 1, 3, 6},
+			want:  []int{10, 1, 6},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			due := throttle{every: tC.every}
+			var got []int
+			for _, tick := range tC.ticks {
+				if due.pass(tick) {
+					got = append(got, tick)
+				}
+			}
+			if !slices.Equal(got, tC.want) {
+				t.Errorf("expected %v, got %v", tC.want, got)
+			}
+		})
+	}
+}
+
+func TestStreamRejectsBadOptions(t *testing.T) {
+	_, _, ts := newTestServer(t)
+	for _, q := range []string{"every=0", "sample=x", "roi=1,2,3"} {
+		resp, err := http.Get(ts.URL + "/api/stream?" + q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", q, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package sim
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+)
+
+type Tool int
+
+const (
+	ToolSpawn Tool = iota
+	ToolErase
+	ToolAttract
+	ToolScatter
+)
+
+var Tools = []Tool{ToolSpawn, ToolErase, ToolAttract, ToolScatter}
+
+var toolNames = [...]string{"spawn", "erase", "attract", "scatter"}
+
+func (t Tool) String() string {
+	if t < 0 || int(t) >= len(toolNames) {
+		return "unknown"
+	}
+	return toolNames[t]
+}
+
+// Stroke is one application of a mouse tool. Applying the same strokes
+// in the same order between the same Updates reproduces a run.
+type Stroke struct {
+	Tool     Tool
+	X, Y     float64
+	Radius   float64
+	Strength float64
+	// Seed places spawned lings.
+	Seed int64
+}
+
+// Apply changes the world by one stroke:
+//   - spawn adds Strength lings (at least one) at random points in the
+//     circle, heading in random directions at speed 1;
+//   - erase removes every ling in the circle;
+//   - attract and scatter change the velocity of lings in the circle by up
+//     to Strength toward or away from the center, fading to nothing at the
+//     edge. Update clamps the result to MaxSpeed.
+func (w *World) Apply(s Stroke) {
+	switch s.Tool {
+	case ToolSpawn:
+		rng := rand.New(rand.NewSource(s.Seed))
+		for range max(1, int(math.Round(s.Strength))) {
+			// sqrt keeps the points uniform over the disc.
+			r := s.Radius * math.Sqrt(rng.Float64())
+			a := rng.Float64() * 2 * math.Pi
+			heading := rng.Float64() * 2 * math.Pi
+			l := Ling{X: s.X + r*math.Cos(a), Y: s.Y + r*math.Sin(a), VX: math.Cos(heading), VY: math.Sin(heading), Size: spawnSize}
+			l.Clamp(float64(w.Width), float64(w.Height))
+			w.Lings = append(w.Lings, l)
+		}
+	case ToolErase:
+		w.Lings = slices.DeleteFunc(w.Lings, func(l Ling) bool {
+			return DistanceSquared(s.X, s.Y, l.X, l.Y) <= s.Radius*s.Radius
+		})
+	case ToolAttract, ToolScatter:
+		strength := s.Strength
+		if s.Tool == ToolScatter {
+			strength = -strength
+		}
+		for i := range w.Lings {
+			l := &w.Lings[i]
+			d := Distance(s.X, s.Y, l.X, l.Y)
+			if d >= s.Radius || d == 0 {
+				continue
+			}
+			f := strength * (1 - d/s.Radius) / d
+			l.VX += (s.X - l.X) * f
+			l.VY += (s.Y - l.Y) * f
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package sim
+
+import (
+	"math"
+	"testing"
+)
+
+func TestApply(t *testing.T) {
+	start := []Ling{{X: 50, Y: 50}, {X: 60, Y: 50}, {X: 90, Y: 90}}
+	testCases := []struct {
+		desc   string
+		stroke Stroke
+		check  func(t *testing.T, w *World)
+	}{
+		{
+			desc:   "spawn adds lings inside the circle",
+			stroke: Stroke{Tool: ToolSpawn, X: 20, Y: 20, Radius: 10, Strength: 4, Seed: 1},
+			check: func(t *testing.T, w *World) {
+				if len(w.Lings) != 7 {
+					t.Fatalf("expected 7 lings, got %d", len(w.Lings))
+				}
+				for _, l := range w.Lings[3:] {
+					if Distance(20, 20, l.X, l.Y) > 10 {
+						t.Errorf("spawned ling at (%v, %v) outside the brush", l.X, l.Y)
+					}
+					if math.Abs(math.Hypot(l.VX, l.VY)-1) > 1e-9 {
+						t.Errorf("expected speed 1, got %v", math.Hypot(l.VX, l.VY))
+					}
+				}
+			},
+		},
+		{
+			desc:   "spawn adds at least one ling",
+			stroke: Stroke{Tool: ToolSpawn, X: 20, Y: 20, Radius: 10},
+			check: func(t *testing.T, w *World) {
+				if len(w.Lings) != 4 {
+					t.Errorf("expected 4 lings, got %d", len(w.Lings))
+				}
+			},
+		},
+		{
+			desc:   "erase removes lings inside the circle",
+			stroke: Stroke{Tool: ToolErase, X: 55, Y: 50, Radius: 5},
+			check: func(t *testing.T, w *World) {
+				if len(w.Lings) != 1 || w.Lings[0].X != 90 {
+					t.Errorf("expected only the far ling left, got %v", w.Lings)
+				}
+			},
+		},
+		{
+			desc:   "attract pulls toward the center",
+			stroke: Stroke{Tool: ToolAttract, X: 40, Y: 50, Radius: 40, Strength: 2},
+			check: func(t *testing.T, w *World) {
+				if w.Lings[0].VX != -1.5 || w.Lings[0].VY != 0 {
+					t.Errorf("expected velocity (-1.5, 0), got (%v, %v)", w.Lings[0].VX, w.Lings[0].VY)
+				}
+				if w.Lings[2].VX != 0 || w.Lings[2].VY != 0 {
+					t.Errorf("expected a ling outside the radius to be untouched, got (%v, %v)", w.Lings[2].VX, w.Lings[2].VY)
+				}
+			},
+		},
+		{
+			desc:   "scatter pushes away from the center",
+			stroke: Stroke{Tool: ToolScatter, X: 40, Y: 50, Radius: 40, Strength: 2},
+			check: func(t *testing.T, w *World) {
+				if w.Lings[1].VX != 1 {
+					t.Errorf("expected VX 1, got %v", w.Lings[1].VX)
+				}
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			world := New(append([]Ling(nil), start...), 100, 100)
+			world.Apply(tC.stroke)
+			tC.check(t, &world)
+		})
+	}
+}
+
+func TestApplyReproducible(t *testing.T) {
+	stroke := Stroke{Tool: ToolSpawn, X: 50, Y: 50, Radius: 30, Strength: 5, Seed: 42}
+	a, b := New(nil, 100, 100), New(nil, 100, 100)
+	a.Apply(stroke)
+	b.Apply(stroke)
+	for i := range a.Lings {
+		if a.Lings[i] != b.Lings[i] {
+			t.Fatalf("ling %d differs: %v vs %v", i, a.Lings[i], b.Lings[i])
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package sim
+
+import (
+	"math/bits"
+	"sort"
+)
+
+// FlockTracker groups lings into flocks: connected components of the graph
+// linking every pair closer than LinkDistance. Flock IDs are carried over
+// from the previous Update by majority overlap, so a flock keeps its ID
+// while it drifts, grows or sheds members.
+type FlockTracker struct {
+	// LinkDistance is the maximum distance between two linked lings. If it
+	// is not positive the world's detection radius is used.
+	LinkDistance float64
+	// IDs holds the flock ID of each ling, parallel to World.Lings.
+	IDs []int
+	// Sizes maps each current flock ID to its member count.
+	Sizes map[int]int
+
+	grid      *Grid
+	parent    []int
+	prevIDs   []int
+	neighbors []int
+	nextID    int
+}
+
+func NewFlockTracker(linkDistance float64) *FlockTracker {
+	return &FlockTracker{LinkDistance: linkDistance, Sizes: make(map[int]int)}
+}
+
+func (t *FlockTracker) find(i int) int {
+	for t.parent[i] != i {
+		t.parent[i] = t.parent[t.parent[i]]
+		i = t.parent[i]
+	}
+	return i
+}
+
+func (t *FlockTracker) union(a, b int) {
+	ra, rb := t.find(a), t.find(b)
+	if ra != rb {
+		t.parent[ra] = rb
+	}
+}
+
+func (t *FlockTracker) Update(w *World) {
+	link := t.LinkDistance
+	if link <= 0 {
+		link = w.DetectionRadius
+	}
+	cellSize := max(link, 1)
+	if t.grid == nil || t.grid.NeedsRebuild(w.Width, w.Height, cellSize) {
+		t.grid = NewGrid(w.Width, w.Height, cellSize)
+	}
+	t.grid.Populate(w.Lings)
+
+	n := len(w.Lings)
+	t.parent = t.parent[:0]
+	for i := range n {
+		t.parent = append(t.parent, i)
+	}
+	linkSq := link * link
+	for i, l := range w.Lings {
+		t.neighbors = t.grid.NeighborIndices(l.X, l.Y, t.neighbors)
+		for _, j := range t.neighbors {
+			if j > i && DistanceSquared(l.X, l.Y, w.Lings[j].X, w.Lings[j].Y) <= linkSq {
+				t.union(i, j)
+			}
+		}
+	}
+
+	members := make(map[int][]int)
+	for i := range n {
+		root := t.find(i)
+		members[root] = append(members[root], i)
+	}
+	roots := make([]int, 0, len(members))
+	for root := range members {
+		roots = append(roots, root)
+	}
+	// Largest flocks pick their previous ID first.
+	sort.Slice(roots, func(a, b int) bool {
+		if len(members[roots[a]]) != len(members[roots[b]]) {
+			return len(members[roots[a]]) > len(members[roots[b]])
+		}
+		return roots[a] < roots[b]
+	})
+
+	t.prevIDs = append(t.prevIDs[:0], t.IDs...)
+	if cap(t.IDs) < n {
+		t.IDs = make([]int, n)
+	}
+	t.IDs = t.IDs[:n]
+	clear(t.Sizes)
+	claimed := make(map[int]bool)
+	votes := make(map[int]int)
+	for _, root := range roots {
+		clear(votes)
+		for _, i := range members[root] {
+			if i < len(t.prevIDs) && !claimed[t.prevIDs[i]] {
+				votes[t.prevIDs[i]]++
+			}
+		}
+		id, best := -1, 0
+		for prev, count := range votes {
+			if count > best || (count == best && prev < id) {
+				id, best = prev, count
+			}
+		}
+		if id < 0 {
+			id = t.nextID
+			t.nextID++
+		}
+		claimed[id] = true
+		for _, i := range members[root] {
+			t.IDs[i] = id
+		}
+		t.Sizes[id] = len(members[root])
+	}
+}
+
+// Count returns the number of flocks found by the last Update.
+func (t *FlockTracker) Count() int {
+	return len(t.Sizes)
+}
+
+// Histogram buckets flock sizes by powers of two: entry k counts flocks
+// with between 2^k and 2^(k+1)-1 members, so entry 0 is lone lings.
+func (t *FlockTracker) Histogram() []int {
+	hist := []int{}
+	for _, size := range t.Sizes {
+		k := bits.Len(uint(size)) - 1
+		for len(hist) <= k {
+			hist = append(hist, 0)
+		}
+		hist[k]++
+	}
+	return hist
+}
@@ -0,0 +1,70 @@
+package sim
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFlockTrackerComponents(t *testing.T) {
+	world := New([]Ling{
+		{X: 10, Y: 10},
+		{X: 20, Y: 10}, // linked to 0
+		{X: 30, Y: 10}, // linked to 1, so chained to 0
+		{X: 200, Y: 200},
+		{X: 205, Y: 200}, // linked to 3
+		{X: 400, Y: 50},  // alone
+	}, 500, 500)
+	tracker := NewFlockTracker(15)
+	tracker.Update(&world)
+
+	if tracker.Count() != 3 {
+		t.Fatalf("expected 3 flocks, got %d (%v)", tracker.Count(), tracker.IDs)
+	}
+	ids := tracker.IDs
+	if ids[0] != ids[1] || ids[1] != ids[2] {
+		t.Errorf("expected lings 0-2 in one flock, got %v", ids)
+	}
+	if ids[3] != ids[4] || ids[3] == ids[0] {
+		t.Errorf("expected lings 3-4 in their own flock, got %v", ids)
+	}
+	if ids[5] == ids[0] || ids[5] == ids[3] {
+		t.Errorf("expected ling 5 alone, got %v", ids)
+	}
+	if got := tracker.Histogram(); !slices.Equal(got, []int{1, 2}) {
+		t.Errorf("expected histogram [1 2], got %v", got)
+	}
+}
+
+func TestFlockTrackerStableIDs(t *testing.T) {
+	world := New([]Ling{
+		{X: 10, Y: 10},
+		{X: 20, Y: 10},
+		{X: 300, Y: 300},
+		{X: 310, Y: 300},
+		{X: 320, Y: 300},
+	}, 500, 500)
+	tracker := NewFlockTracker(15)
+	tracker.Update(&world)
+	small, large := tracker.IDs[0], tracker.IDs[2]
+
+	// Both flocks drift and the large one sheds a member.
+	for i := range world.Lings {
+		world.Lings[i].X += 30
+		world.Lings[i].Y += 5
+	}
+	world.Lings[4].X += 100
+	tracker.Update(&world)
+
+	if tracker.IDs[0] != small || tracker.IDs[1] != small {
+		t.Errorf("expected small flock to keep ID %d, got %v", small, tracker.IDs)
+	}
+	if tracker.IDs[2] != large || tracker.IDs[3] != large {
+		t.Errorf("expected large flock to keep ID %d, got %v", large, tracker.IDs)
+	}
+	if id := tracker.IDs[4]; id == small || id == large {
+		t.Errorf("expected the straggler to get a new ID, got %d", id)
+	}
+	if tracker.Sizes[large] != 2 {
+		t.Errorf("expected large flock to have 2 members, got %d", tracker.Sizes[large])
+	}
+}
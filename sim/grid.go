@@ -89,3 +89,92 @@ func (g *Grid) NeedsRebuild(width, height int, cellSize float64) bool {
 	newRows := int(math.Ceil(float64(height) / cellSize))
 	return g.cols != newCols || g.rows != newRows || g.cellSize != cellSize
 }
+
+func (g *Grid) cellOf(x, y float64) (col, row int) {
+	col = int(x / g.cellSize)
+	row = int(y / g.cellSize)
+	col = max(0, min(g.cols-1, col))
+	row = max(0, min(g.rows-1, row))
+	return col, row
+}
+
+// Nearest returns the index of the ling closest to (x, y) and its distance,
+// searching outward ring by ring from the containing cell. It returns -1 if
+// there is no other ling.
+func (g *Grid) Nearest(x, y float64, excludeIndex int, lings []Ling) (int, float64) {
+	col, row := g.cellOf(x, y)
+	best, bestSq := -1, math.Inf(1)
+	maxRing := max(g.cols, g.rows)
+	for r := 0; r <= maxRing; r++ {
+		for nr := row - r; nr <= row+r; nr++ {
+			if nr < 0 || nr >= g.rows {
+				continue
+			}
+			for nc := col - r; nc <= col+r; nc++ {
+				if nc < 0 || nc >= g.cols {
+					continue
+				}
+				// Only the outer ring is new at this radius.
+				if nr != row-r && nr != row+r && nc != col-r && nc != col+r {
+					continue
+				}
+				for _, bi := range g.cells[nr*g.cols+nc] {
+					if bi == excludeIndex {
+						continue
+					}
+					if d := DistanceSquared(x, y, lings[bi].X, lings[bi].Y); d < bestSq {
+						best, bestSq = bi, d
+					}
+				}
+			}
+		}
+		// Anything in ring r+1 is at least r cells away.
+		reach := float64(r) * g.cellSize
+		if best >= 0 && bestSq <= reach*reach {
+			break
+		}
+	}
+	if best < 0 {
+		return -1, 0
+	}
+	return best, math.Sqrt(bestSq)
+}
+
+// NeighborIndices appends the indices of every ling in the 3x3 block of
+// cells around (x, y) to buf[:0].
+func (g *Grid) NeighborIndices(x, y float64, buf []int) []int {
+	buf = buf[:0]
+	col, row := g.cellOf(x, y)
+	for nr := max(0, row-1); nr <= min(g.rows-1, row+1); nr++ {
+		for nc := max(0, col-1); nc <= min(g.cols-1, col+1); nc++ {
+			buf = append(buf, g.cells[nr*g.cols+nc]...)
+		}
+	}
+	return buf
+}
+
+// GridStats summarizes how lings are spread over the grid cells.
+type GridStats struct {
+	Cells int
+	Empty int
+	Max   int
+	// MeanOccupied is the mean ling count of the non-empty cells.
+	MeanOccupied float64
+}
+
+func (g *Grid) Stats() GridStats {
+	st := GridStats{Cells: len(g.cells)}
+	total := 0
+	for _, c := range g.cells {
+		if len(c) == 0 {
+			st.Empty++
+			continue
+		}
+		total += len(c)
+		st.Max = max(st.Max, len(c))
+	}
+	if occupied := st.Cells - st.Empty; occupied > 0 {
+		st.MeanOccupied = float64(total) / float64(occupied)
+	}
+	return st
+}
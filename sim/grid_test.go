@@ -1,6 +1,8 @@
 package sim
 
 import (
+	"math"
+	"math/rand"
 	"testing"
 )
 
@@ -156,3 +158,59 @@ func TestNeedsRebuild(t *testing.T) {
 		t.Error("expected rebuild needed for different cellSize")
 	}
 }
+
+func TestNearest(t *testing.T) {
+	g := NewGrid(400, 400, 50)
+	lings := []Ling{
+		{X: 10, Y: 10},
+		{X: 390, Y: 390}, // many rings away
+		{X: 300, Y: 20},
+	}
+	g.Populate(lings)
+
+	idx, d := g.Nearest(10, 10, 0, lings)
+	if idx != 2 || math.Abs(d-math.Hypot(290, 10)) > 1e-9 {
+		t.Errorf("expected ling 2 at distance %f, got ling %d at %f", math.Hypot(290, 10), idx, d)
+	}
+
+	g.Populate(lings[:1])
+	idx, _ = g.Nearest(10, 10, 0, lings[:1])
+	if idx != -1 {
+		t.Errorf("expected -1 with no other lings, got %d", idx)
+	}
+}
+
+func TestNearestMatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	lings := make([]Ling, 300)
+	for i := range lings {
+		lings[i] = Ling{X: rng.Float64() * 1000, Y: rng.Float64() * 800}
+	}
+	g := NewGrid(1000, 800, 60)
+	g.Populate(lings)
+	for i, l := range lings {
+		want := math.Inf(1)
+		for j, o := range lings {
+			if i != j {
+				want = math.Min(want, Distance(l.X, l.Y, o.X, o.Y))
+			}
+		}
+		if _, got := g.Nearest(l.X, l.Y, i, lings); math.Abs(got-want) > 1e-9 {
+			t.Fatalf("ling %d: expected nearest distance %f, got %f", i, want, got)
+		}
+	}
+}
+
+func TestGridStats(t *testing.T) {
+	g := NewGrid(100, 100, 50) // 2x2
+	g.Populate([]Ling{
+		{X: 10, Y: 10},
+		{X: 20, Y: 20},
+		{X: 30, Y: 10},
+		{X: 60, Y: 60},
+	})
+	st := g.Stats()
+	if st.Cells != 4 || st.Empty != 2 || st.Max != 3 || st.MeanOccupied != 2 {
+		t.Errorf("unexpected stats %+v", st)
+	}
+}
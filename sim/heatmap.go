@@ -0,0 +1,82 @@
+package sim
+
+import "math"
+
+// Heatmap counts lings per square cell over the world, row-major, binned
+// the same way as Grid.
+type Heatmap struct {
+	Cols, Rows int
+	CellSize   float64
+	Values     []float64
+	scratch    []float64
+}
+
+func NewHeatmap(width, height int, cellSize float64) *Heatmap {
+	cellSize = max(cellSize, 1)
+	cols := max(1, int(math.Ceil(float64(width)/cellSize)))
+	rows := max(1, int(math.Ceil(float64(height)/cellSize)))
+	return &Heatmap{Cols: cols, Rows: rows, CellSize: cellSize, Values: make([]float64, cols*rows)}
+}
+
+// Fits reports whether h covers a width x height world with cells of
+// cellSize, so callers know when to replace it.
+func (h *Heatmap) Fits(width, height int, cellSize float64) bool {
+	cellSize = max(cellSize, 1)
+	return h.CellSize == cellSize &&
+		h.Cols == max(1, int(math.Ceil(float64(width)/cellSize))) &&
+		h.Rows == max(1, int(math.Ceil(float64(height)/cellSize)))
+}
+
+func (h *Heatmap) Clear() {
+	clear(h.Values)
+}
+
+// Add counts each ling once in the cell it is in.
+func (h *Heatmap) Add(lings []Ling) {
+	for _, l := range lings {
+		col := max(0, min(h.Cols-1, int(l.X/h.CellSize)))
+		row := max(0, min(h.Rows-1, int(l.Y/h.CellSize)))
+		h.Values[row*h.Cols+col]++
+	}
+}
+
+// Blur smooths the values with a separable box filter radius cells wide,
+// averaging only over cells inside the map so edges aren't darkened.
+func (h *Heatmap) Blur(radius int) {
+	if radius <= 0 {
+		return
+	}
+	if len(h.scratch) != len(h.Values) {
+		h.scratch = make([]float64, len(h.Values))
+	}
+	// Horizontal pass into scratch, vertical pass back into Values.
+	for r := range h.Rows {
+		row := h.Values[r*h.Cols : (r+1)*h.Cols]
+		for c := range h.Cols {
+			lo, hi := max(0, c-radius), min(h.Cols-1, c+radius)
+			var sum float64
+			for k := lo; k <= hi; k++ {
+				sum += row[k]
+			}
+			h.scratch[r*h.Cols+c] = sum / float64(hi-lo+1)
+		}
+	}
+	for c := range h.Cols {
+		for r := range h.Rows {
+			lo, hi := max(0, r-radius), min(h.Rows-1, r+radius)
+			var sum float64
+			for k := lo; k <= hi; k++ {
+				sum += h.scratch[k*h.Cols+c]
+			}
+			h.Values[r*h.Cols+c] = sum / float64(hi-lo+1)
+		}
+	}
+}
+
+func (h *Heatmap) Max() float64 {
+	var m float64
+	for _, v := range h.Values {
+		m = math.Max(m, v)
+	}
+	return m
+}
@@ -0,0 +1,45 @@
+package sim
+
+import "testing"
+
+func TestHeatmap(t *testing.T) {
+	lings := []Ling{{X: 5, Y: 5}, {X: 8, Y: 2}, {X: 35, Y: 15}, {X: 100, Y: 100}}
+	testCases := []struct {
+		desc   string
+		blur   int
+		cell   [2]int
+		want   float64
+		maxVal float64
+	}{
+		{desc: "counts lings per cell", cell: [2]int{0, 0}, want: 2, maxVal: 2},
+		{desc: "clamps lings on the far edge", cell: [2]int{3, 1}, want: 2, maxVal: 2},
+		{desc: "blur averages over in-bounds neighbors", blur: 1, cell: [2]int{0, 0}, want: 0.5, maxVal: 0.5},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			h := NewHeatmap(40, 20, 10)
+			if h.Cols != 4 || h.Rows != 2 {
+				t.Fatalf("expected 4x2 cells, got %dx%d", h.Cols, h.Rows)
+			}
+			h.Add(lings)
+			h.Blur(tC.blur)
+			col, row := tC.cell[0], tC.cell[1]
+			if got := h.Values[row*h.Cols+col]; got != tC.want {
+				t.Errorf("expected %v in cell (%d, %d), got %v", tC.want, col, row, got)
+			}
+			if got := h.Max(); got != tC.maxVal {
+				t.Errorf("expected max %v, got %v", tC.maxVal, got)
+			}
+		})
+	}
+}
+
+func TestHeatmapFits(t *testing.T) {
+	h := NewHeatmap(40, 20, 10)
+	if !h.Fits(40, 20, 10) || !h.Fits(35, 15, 10) {
+		t.Error("expected the map to fit worlds with the same cell layout")
+	}
+	if h.Fits(50, 20, 10) || h.Fits(40, 20, 5) {
+		t.Error("expected a different layout not to fit")
+	}
+}
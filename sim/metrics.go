@@ -0,0 +1,99 @@
+package sim
+
+import "math"
+
+// Metrics are the standard collective-motion order parameters for one
+// snapshot of the world.
+type Metrics struct {
+	Tick       int `json:"tick"`
+	Population int `json:"population"`
+	// Polarization is the length of the mean heading vector: 1 when every
+	// ling points the same way, near 0 when headings are random.
+	Polarization float64 `json:"polarization"`
+	// Milling is the normalized angular momentum about the flock centroid:
+	// near 1 when lings circle the centroid in the same direction.
+	Milling float64 `json:"milling"`
+	// MeanNearestNeighbor is the mean distance from each ling to its
+	// closest other ling.
+	MeanNearestNeighbor float64 `json:"mean_nn_distance"`
+	AverageSpeed        float64 `json:"avg_speed"`
+	// Density is lings per 10,000 square units of world area.
+	Density float64 `json:"density"`
+}
+
+// Metrics computes the order parameters from the current ling positions.
+func (w *World) Metrics() Metrics {
+	m := Metrics{Tick: w.Tick, Population: len(w.Lings)}
+	n := len(w.Lings)
+	if n == 0 {
+		return m
+	}
+	if area := float64(w.Width) * float64(w.Height); area > 0 {
+		m.Density = float64(n) / area * 10000
+	}
+
+	var cx, cy float64
+	for _, l := range w.Lings {
+		cx += l.X
+		cy += l.Y
+	}
+	cx /= float64(n)
+	cy /= float64(n)
+
+	var hx, hy, angular, speeds float64
+	for _, l := range w.Lings {
+		speed := math.Hypot(l.VX, l.VY)
+		speeds += speed
+		if speed == 0 {
+			continue
+		}
+		ux, uy := l.VX/speed, l.VY/speed
+		hx += ux
+		hy += uy
+		rx, ry := l.X-cx, l.Y-cy
+		if r := math.Hypot(rx, ry); r > 0 {
+			angular += (rx*uy - ry*ux) / r
+		}
+	}
+	m.Polarization = math.Hypot(hx, hy) / float64(n)
+	m.Milling = math.Abs(angular) / float64(n)
+	m.AverageSpeed = speeds / float64(n)
+
+	if n > 1 {
+		w.populateGrid()
+		var total float64
+		for i, l := range w.Lings {
+			_, d := w.grid.Nearest(l.X, l.Y, i, w.Lings)
+			total += d
+		}
+		m.MeanNearestNeighbor = total / float64(n)
+	}
+	return m
+}
+
+// NeighborCounts returns how many other lings are within the detection
+// radius of each ling, reusing dst if it is large enough.
+func (w *World) NeighborCounts(dst []int) []int {
+	if cap(dst) < len(w.Lings) {
+		dst = make([]int, len(w.Lings))
+	}
+	dst = dst[:len(w.Lings)]
+	if len(w.Lings) == 0 {
+		return dst
+	}
+	w.populateGrid()
+	r2 := w.DetectionRadius * w.DetectionRadius
+	var idx []int
+	for i, l := range w.Lings {
+		idx = w.grid.NeighborIndices(l.X, l.Y, idx)
+		n := 0
+		for _, j := range idx {
+			dx, dy := w.Lings[j].X-l.X, w.Lings[j].Y-l.Y
+			if j != i && dx*dx+dy*dy <= r2 {
+				n++
+			}
+		}
+		dst[i] = n
+	}
+	return dst
+}
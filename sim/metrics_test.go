@@ -0,0 +1,99 @@
+package sim
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	ring := make([]Ling, 36)
+	for i := range ring {
+		a := float64(i) / float64(len(ring)) * 2 * math.Pi
+		// Tangential velocity, counter-clockwise around (500, 500).
+		ring[i] = Ling{X: 500 + 100*math.Cos(a), Y: 500 + 100*math.Sin(a), VX: -2 * math.Sin(a), VY: 2 * math.Cos(a)}
+	}
+	aligned := []Ling{
+		{X: 100, Y: 100, VX: 1, VY: 0},
+		{X: 110, Y: 100, VX: 3, VY: 0},
+		{X: 120, Y: 100, VX: 2, VY: 0},
+	}
+
+	testCases := []struct {
+		desc     string
+		lings    []Ling
+		expected Metrics
+	}{
+		{
+			desc:     "empty world has zero metrics",
+			lings:    nil,
+			expected: Metrics{},
+		},
+		{
+			desc:  "lings heading the same way are fully polarized",
+			lings: aligned,
+			expected: Metrics{
+				Population:          3,
+				Polarization:        1,
+				MeanNearestNeighbor: 10,
+				AverageSpeed:        2,
+				Density:             3.0 / (1000 * 1000) * 10000,
+			},
+		},
+		{
+			desc:  "lings circling the centroid are milling, not polarized",
+			lings: ring,
+			expected: Metrics{
+				Population:          36,
+				Polarization:        0,
+				Milling:             1,
+				MeanNearestNeighbor: 2 * 100 * math.Sin(math.Pi/36),
+				AverageSpeed:        2,
+				Density:             36.0 / (1000 * 1000) * 10000,
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			world := New(tC.lings, 1000, 1000)
+			got := world.Metrics()
+			fields := []struct {
+				name      string
+				got, want float64
+			}{
+				{"Population", float64(got.Population), float64(tC.expected.Population)},
+				{"Polarization", got.Polarization, tC.expected.Polarization},
+				{"Milling", got.Milling, tC.expected.Milling},
+				{"MeanNearestNeighbor", got.MeanNearestNeighbor, tC.expected.MeanNearestNeighbor},
+				{"AverageSpeed", got.AverageSpeed, tC.expected.AverageSpeed},
+				{"Density", got.Density, tC.expected.Density},
+			}
+			for _, f := range fields {
+				if math.Abs(f.got-f.want) > 1e-9 {
+					t.Errorf("expected %s=%v, got %v", f.name, f.want, f.got)
+				}
+			}
+		})
+	}
+}
+
+func TestMetricsTick(t *testing.T) {
+	world := New([]Ling{{X: 10, Y: 10, VX: 1}}, 100, 100)
+	world.Update()
+	world.Update()
+	if m := world.Metrics(); m.Tick != 2 {
+		t.Errorf("expected tick 2, got %d", m.Tick)
+	}
+}
+
+func TestNeighborCounts(t *testing.T) {
+	lings := []Ling{{X: 100, Y: 100}, {X: 130, Y: 100}, {X: 100, Y: 160}, {X: 400, Y: 400}}
+	w := New(lings, 500, 500)
+	w.DetectionRadius = 50
+	got := w.NeighborCounts(nil)
+	want := []int{1, 1, 0, 0}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("ling %d: expected %d neighbors, got %d", i, want[i], got[i])
+		}
+	}
+}
@@ -1,11 +1,15 @@
 package sim
 
-import "math"
+import (
+	"log/slog"
+	"math"
+)
 
 type World struct {
 	Lings           []Ling
 	Width           int
 	Height          int
+	Tick            int
 	AvoidanceFactor float64
 	AlignmentFactor float64
 	GatheringFactor float64
@@ -14,8 +18,15 @@ type World struct {
 	MaxSpeed        float64
 	WallMargin      float64
 	WallForce       float64
+	Trails          *Trails
+	RecordSteering  bool
+	Steering        []Steering
+	Inspect         int
+	Inspected       Steering
 	grid            *Grid
 	neighbors       []Ling
+	candidates      []int
+	population      int
 }
 
 func New(lings []Ling, w, h int) World {
@@ -31,33 +42,63 @@ func New(lings []Ling, w, h int) World {
 		MaxSpeed:        3,
 		WallMargin:      75,
 		WallForce:       1.5,
+		Inspect:         -1,
 	}
 }
 
 func (w *World) Update() {
-	cellSize := w.DetectionRadius
-	if cellSize < 1 {
-		cellSize = 1
+	// The first Update only records the starting population. Brushes change
+	// it every tick while held, so this is only a debug message.
+	if n := len(w.Lings); n != w.population {
+		if w.Tick > 0 {
+			slog.Debug("population changed", "tick", w.Tick, "from", w.population, "to", n)
+		}
+		w.population = n
 	}
+	w.populateGrid()
 
-	if w.grid == nil || w.grid.NeedsRebuild(w.Width, w.Height, cellSize) {
-		w.grid = NewGrid(w.Width, w.Height, cellSize)
+	if w.RecordSteering {
+		w.Steering = growSteering(w.Steering, len(w.Lings))
 	}
-	w.grid.Populate(w.Lings)
 
 	for i := range w.Lings {
 		w.neighbors = w.grid.Neighbors(w.Lings[i].X, w.Lings[i].Y, i, w.Lings, w.neighbors)
+		var st *Steering
+		switch {
+		case w.RecordSteering:
+			st = &w.Steering[i]
+		case i == w.Inspect:
+			st = &w.Inspected
+		}
+		if st != nil {
+			st.Neighbors = w.neighborsWithin(i, w.DetectionRadius, st.Neighbors)
+		}
 
 		vx, vy := w.Lings[i].Avoid(w.neighbors, w.AvoidanceFactor, w.AvoidanceRadius)
 		vx2, vy2 := w.Lings[i].Align(w.neighbors, w.AlignmentFactor, w.DetectionRadius)
+		if st != nil {
+			st.Avoid, st.Align = Vec{vx, vy}, Vec{vx2, vy2}
+		}
 		vx += vx2
 		vy += vy2
 		vx2, vy2 = w.Lings[i].Gather(w.neighbors, w.GatheringFactor, w.DetectionRadius)
+		if st != nil {
+			st.Gather = Vec{vx2, vy2}
+		}
 		vx += vx2
 		vy += vy2
 		vx2, vy2 = w.Lings[i].WallAvoid(float64(w.Width), float64(w.Height), w.WallMargin, w.WallForce)
+		if st != nil {
+			st.Wall = Vec{vx2, vy2}
+		}
 		vx += vx2
 		vy += vy2
+		if w.RecordSteering && i == w.Inspect {
+			// Copy the neighbors so Inspected never shares Steering's buffers.
+			neighbors := append(w.Inspected.Neighbors[:0], st.Neighbors...)
+			w.Inspected = *st
+			w.Inspected.Neighbors = neighbors
+		}
 		w.Lings[i].VX += vx
 		w.Lings[i].VY += vy
 		speed := math.Hypot(w.Lings[i].VX, w.Lings[i].VY)
@@ -69,6 +110,63 @@ func (w *World) Update() {
 		w.Lings[i].Move()
 		w.Lings[i].Clamp(float64(w.Width), float64(w.Height))
 	}
+	if w.Trails != nil {
+		w.Trails.Record(w.Lings)
+	}
+	w.Tick++
+}
+
+// populateGrid fills the grid with the current positions, rebuilding it
+// first if the bounds or detection radius changed.
+func (w *World) populateGrid() {
+	cellSize := max(w.DetectionRadius, 1)
+	if w.grid == nil || w.grid.NeedsRebuild(w.Width, w.Height, cellSize) {
+		w.grid = NewGrid(w.Width, w.Height, cellSize)
+		slog.Debug("grid rebuilt", "width", w.Width, "height", w.Height, "cell_size", cellSize, "cols", w.grid.cols, "rows", w.grid.rows)
+	}
+	w.grid.Populate(w.Lings)
+}
+
+// GridStats reports cell occupancy as of the last Update.
+func (w *World) GridStats() GridStats {
+	if w.grid == nil {
+		return GridStats{}
+	}
+	return w.grid.Stats()
+}
+
+// LingAt returns the index of the ling closest to x, y within r, or -1
+// if there is none.
+func (w *World) LingAt(x, y, r float64) int {
+	best, bestD := -1, r*r
+	for i, l := range w.Lings {
+		if d := DistanceSquared(x, y, l.X, l.Y); d <= bestD {
+			best, bestD = i, d
+		}
+	}
+	return best
+}
+
+// Resize changes the world bounds. With rescale every position is scaled
+// along with the bounds; without it lings stay where they are and any left
+// outside are clamped back in. The grid is rebuilt for the new bounds.
+func (w *World) Resize(width, height int, rescale bool) {
+	if width == w.Width && height == w.Height {
+		return
+	}
+	slog.Debug("world resized", "from_width", w.Width, "from_height", w.Height, "width", width, "height", height, "rescale", rescale)
+	if rescale && w.Width > 0 && w.Height > 0 {
+		w.UpdatePositions(float64(width)/float64(w.Width), float64(height)/float64(w.Height))
+	}
+	w.Width, w.Height = width, height
+	w.Trails.Reset()
+	for i := range w.Lings {
+		w.Lings[i].Clamp(float64(width), float64(height))
+	}
+	if w.grid != nil {
+		w.grid = NewGrid(width, height, w.grid.cellSize)
+		w.grid.Populate(w.Lings)
+	}
 }
 
 func (w *World) UpdatePositions(ratioX, ratioY float64) {
@@ -126,3 +126,53 @@ func BenchmarkUpdate(b *testing.B) {
 		})
 	}
 }
+
+func TestResize(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		width   int
+		height  int
+		rescale bool
+		want    [2]float64
+	}{
+		{desc: "stretch scales positions per axis", width: 200, height: 50, rescale: true, want: [2]float64{160, 20}},
+		{desc: "growing keeps positions", width: 200, height: 200, want: [2]float64{80, 40}},
+		{desc: "shrinking clamps lings inside", width: 50, height: 30, want: [2]float64{50, 30}},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			world := New([]Ling{{X: 80, Y: 40, VX: 1, VY: 1}}, 100, 100)
+			world.Update()
+			world.Lings[0].X, world.Lings[0].Y = 80, 40
+			world.Resize(tC.width, tC.height, tC.rescale)
+			l := world.Lings[0]
+			if l.X != tC.want[0] || l.Y != tC.want[1] {
+				t.Errorf("expected ling at %v, got (%v, %v)", tC.want, l.X, l.Y)
+			}
+			if world.grid.NeedsRebuild(tC.width, tC.height, world.DetectionRadius) {
+				t.Error("expected the grid to match the new bounds")
+			}
+		})
+	}
+}
+
+func TestLingAt(t *testing.T) {
+	world := New([]Ling{{X: 10, Y: 10}, {X: 20, Y: 10}, {X: 50, Y: 50}}, 100, 100)
+	testCases := []struct {
+		desc string
+		x, y float64
+		r    float64
+		want int
+	}{
+		{desc: "closest of two in range", x: 17, y: 10, r: 10, want: 1},
+		{desc: "exactly at the radius", x: 50, y: 55, r: 5, want: 2},
+		{desc: "nothing in range", x: 90, y: 90, r: 10, want: -1},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			if got := world.LingAt(tC.x, tC.y, tC.r); got != tC.want {
+				t.Errorf("expected %d, got %d", tC.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package sim
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Distribution is how a Spawner places lings in the world.
+type Distribution string
+
+const (
+	SpawnUniform   Distribution = "uniform"
+	SpawnClustered Distribution = "clustered"
+	SpawnRing      Distribution = "ring"
+	SpawnGrid      Distribution = "grid"
+)
+
+var Distributions = []Distribution{SpawnUniform, SpawnClustered, SpawnRing, SpawnGrid}
+
+// VelocityMode is how a Spawner sets starting velocities.
+type VelocityMode string
+
+const (
+	VelocityRandom  VelocityMode = "random"
+	VelocityAligned VelocityMode = "aligned"
+	VelocityZero    VelocityMode = "zero"
+)
+
+var VelocityModes = []VelocityMode{VelocityRandom, VelocityAligned, VelocityZero}
+
+const (
+	spawnSize = 5
+	// spawnClusters is how many groups Clustered starts with.
+	spawnClusters = 5
+)
+
+// Spawner builds starting populations. The zero Distribution and
+// VelocityMode mean SpawnUniform and VelocityRandom.
+type Spawner struct {
+	Population   int
+	Distribution Distribution
+	Velocity     VelocityMode
+}
+
+// Spawn places s.Population lings in a width x height world. The same seed
+// gives the same lings.
+func (s Spawner) Spawn(width, height int, seed int64) []Ling {
+	rng := rand.New(rand.NewSource(seed))
+	w, h := float64(width), float64(height)
+	lings := make([]Ling, s.Population)
+	// Uniform random spawns draw from rng in the same order the app always
+	// has, so existing seeds keep their worlds.
+	var heading float64
+	if s.Velocity == VelocityAligned {
+		heading = rng.Float64() * 2 * math.Pi
+	}
+
+	var centers [][2]float64
+	if s.Distribution == SpawnClustered {
+		centers = make([][2]float64, spawnClusters)
+		for i := range centers {
+			centers[i] = [2]float64{w * (0.15 + 0.7*rng.Float64()), h * (0.15 + 0.7*rng.Float64())}
+		}
+	}
+	cols := max(1, int(math.Ceil(math.Sqrt(float64(s.Population)*w/h))))
+	rows := max(1, (s.Population+cols-1)/cols)
+
+	for i := range lings {
+		l := &lings[i]
+		l.Size = spawnSize
+		switch s.Distribution {
+		case SpawnClustered:
+			c := centers[i%len(centers)]
+			spread := math.Min(w, h) / 20
+			l.X = c[0] + rng.NormFloat64()*spread
+			l.Y = c[1] + rng.NormFloat64()*spread
+		case SpawnRing:
+			a := rng.Float64() * 2 * math.Pi
+			r := math.Min(w, h) * (0.35 + 0.03*rng.NormFloat64())
+			l.X, l.Y = w/2+r*math.Cos(a), h/2+r*math.Sin(a)
+		case SpawnGrid:
+			l.X = (float64(i%cols) + 0.5) * w / float64(cols)
+			l.Y = (float64(i/cols) + 0.5) * h / float64(rows)
+		default:
+			l.X, l.Y = rng.Float64()*w, rng.Float64()*h
+		}
+		switch s.Velocity {
+		case VelocityAligned:
+			l.VX, l.VY = math.Cos(heading), math.Sin(heading)
+		case VelocityZero:
+		default:
+			l.VX, l.VY = rng.Float64(), rng.Float64()
+		}
+		l.Clamp(w, h)
+	}
+	return lings
+}
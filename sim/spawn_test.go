@@ -0,0 +1,92 @@
+package sim
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSpawn(t *testing.T) {
+	const width, height = 400, 300
+	testCases := []struct {
+		desc    string
+		spawner Spawner
+		check   func(t *testing.T, lings []Ling)
+	}{
+		{
+			desc:    "ring keeps lings near the ring",
+			spawner: Spawner{Population: 200, Distribution: SpawnRing},
+			check: func(t *testing.T, lings []Ling) {
+				for _, l := range lings {
+					if r := Distance(width/2, height/2, l.X, l.Y); math.Abs(r-105) > 40 {
+						t.Fatalf("ling at radius %v, expected about 105", r)
+					}
+				}
+			},
+		},
+		{
+			desc:    "grid spaces lings evenly",
+			spawner: Spawner{Population: 12, Distribution: SpawnGrid},
+			check: func(t *testing.T, lings []Ling) {
+				// 12 lings in a 4:3 world make a 4x3 grid.
+				if lings[0].X != 50 || lings[0].Y != 50 || lings[11].X != 350 || lings[11].Y != 250 {
+					t.Errorf("expected corners at (50, 50) and (350, 250), got (%v, %v) and (%v, %v)", lings[0].X, lings[0].Y, lings[11].X, lings[11].Y)
+				}
+			},
+		},
+		{
+			desc:    "clustered lings stay in the world",
+			spawner: Spawner{Population: 500, Distribution: SpawnClustered},
+			check: func(t *testing.T, lings []Ling) {
+				for _, l := range lings {
+					if l.X < 0 || l.X > width || l.Y < 0 || l.Y > height {
+						t.Fatalf("ling at (%v, %v) outside the world", l.X, l.Y)
+					}
+				}
+			},
+		},
+		{
+			desc:    "aligned velocities share a heading",
+			spawner: Spawner{Population: 50, Velocity: VelocityAligned},
+			check: func(t *testing.T, lings []Ling) {
+				for _, l := range lings {
+					if l.VX != lings[0].VX || l.VY != lings[0].VY {
+						t.Fatalf("expected every velocity to be (%v, %v), got (%v, %v)", lings[0].VX, lings[0].VY, l.VX, l.VY)
+					}
+				}
+				if math.Abs(math.Hypot(lings[0].VX, lings[0].VY)-1) > 1e-9 {
+					t.Errorf("expected speed 1, got %v", math.Hypot(lings[0].VX, lings[0].VY))
+				}
+			},
+		},
+		{
+			desc:    "zero velocities",
+			spawner: Spawner{Population: 50, Velocity: VelocityZero},
+			check: func(t *testing.T, lings []Ling) {
+				for _, l := range lings {
+					if l.VX != 0 || l.VY != 0 {
+						t.Fatalf("expected zero velocity, got (%v, %v)", l.VX, l.VY)
+					}
+				}
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			lings := tC.spawner.Spawn(width, height, 3)
+			if len(lings) != tC.spawner.Population {
+				t.Fatalf("expected %d lings, got %d", tC.spawner.Population, len(lings))
+			}
+			tC.check(t, lings)
+		})
+	}
+}
+
+func TestSpawnSeed(t *testing.T) {
+	s := Spawner{Population: 100, Distribution: SpawnClustered}
+	a, b := s.Spawn(400, 300, 9), s.Spawn(400, 300, 9)
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("ling %d differs: %v vs %v", i, a[i], b[i])
+		}
+	}
+}
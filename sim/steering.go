@@ -0,0 +1,68 @@
+package sim
+
+type Vec struct {
+	X, Y float64
+}
+
+// Steering breaks down the velocity change one ling got in the last
+// Update. World.Steering holds one per ling while World.RecordSteering is
+// set; World.Inspected holds just the one for World.Inspect, which costs next
+// to nothing and is how a single ling is watched. New sets Inspect to -1.
+type Steering struct {
+	Avoid, Align, Gather, Wall Vec
+	// Neighbors are the indices of the lings within the detection radius,
+	// the ones Align and Gather averaged over.
+	Neighbors []int
+}
+
+func (s Steering) Total() Vec {
+	return Vec{s.Avoid.X + s.Align.X + s.Gather.X + s.Wall.X, s.Avoid.Y + s.Align.Y + s.Gather.Y + s.Wall.Y}
+}
+
+// growSteering resizes s to n entries, keeping each entry's neighbor slice
+// for reuse.
+func growSteering(s []Steering, n int) []Steering {
+	if cap(s) < n {
+		s = append(s[:cap(s)], make([]Steering, n-cap(s))...)
+	}
+	return s[:n]
+}
+
+// neighborsWithin appends to buf[:0] the indices of the lings strictly
+// within r of ling i, matching the distance test in Align and Gather.
+func (w *World) neighborsWithin(i int, r float64, buf []int) []int {
+	l := w.Lings[i]
+	candidates := w.grid.NeighborIndices(l.X, l.Y, w.candidates)
+	w.candidates = candidates
+	buf = buf[:0]
+	for _, j := range candidates {
+		if j != i && DistanceSquared(l.X, l.Y, w.Lings[j].X, w.Lings[j].Y) < r*r {
+			buf = append(buf, j)
+		}
+	}
+	return buf
+}
+
+// GridView is a snapshot of the spatial grid as of the last Update.
+type GridView struct {
+	Cols, Rows int
+	CellSize   float64
+	// Counts holds the number of lings per cell, row-major.
+	Counts []int
+}
+
+// GridView fills counts (reused if large enough) from the grid.
+func (w *World) GridView(counts []int) GridView {
+	if w.grid == nil {
+		return GridView{Counts: counts[:0]}
+	}
+	g := w.grid
+	if cap(counts) < len(g.cells) {
+		counts = make([]int, len(g.cells))
+	}
+	counts = counts[:len(g.cells)]
+	for i, c := range g.cells {
+		counts[i] = len(c)
+	}
+	return GridView{Cols: g.cols, Rows: g.rows, CellSize: g.cellSize, Counts: counts}
+}
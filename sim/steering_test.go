@@ -0,0 +1,105 @@
+package sim
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSteering(t *testing.T) {
+	lings := []Ling{
+		{X: 100, Y: 100, VX: 0.5},
+		{X: 110, Y: 100, VY: 0.5},
+		{X: 150, Y: 100},
+		{X: 400, Y: 400},
+	}
+	w := New(append([]Ling(nil), lings...), 500, 500)
+	w.DetectionRadius = 45
+	w.RecordSteering = true
+	w.Update()
+
+	testCases := []struct {
+		desc      string
+		ling      int
+		neighbors []int
+	}{
+		{desc: "close pair sees each other", ling: 0, neighbors: []int{1}},
+		{desc: "middle ling sees both sides", ling: 1, neighbors: []int{0, 2}},
+		{desc: "isolated ling has no neighbors", ling: 3, neighbors: nil},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			st := w.Steering[tC.ling]
+			if len(st.Neighbors) != len(tC.neighbors) {
+				t.Fatalf("expected neighbors %v, got %v", tC.neighbors, st.Neighbors)
+			}
+			for _, j := range tC.neighbors {
+				found := false
+				for _, got := range st.Neighbors {
+					found = found || got == j
+				}
+				if !found {
+					t.Errorf("expected neighbor %d in %v", j, st.Neighbors)
+				}
+			}
+			// Nothing here reaches max speed, so the recorded terms add up to
+			// the velocity change.
+			total := st.Total()
+			dvx := w.Lings[tC.ling].VX - lings[tC.ling].VX
+			dvy := w.Lings[tC.ling].VY - lings[tC.ling].VY
+			if math.Abs(total.X-dvx) > 1e-12 || math.Abs(total.Y-dvy) > 1e-12 {
+				t.Errorf("expected total %v to match velocity change (%v, %v)", total, dvx, dvy)
+			}
+		})
+	}
+}
+
+func TestGridView(t *testing.T) {
+	w := New([]Ling{{X: 10, Y: 10}, {X: 20, Y: 20}, {X: 180, Y: 80}}, 200, 100)
+	if v := w.GridView(nil); v.Cols != 0 {
+		t.Errorf("expected an empty view before the first Update, got %+v", v)
+	}
+	w.DetectionRadius = 50
+	w.Update()
+	v := w.GridView(nil)
+	if v.Cols != 4 || v.Rows != 2 || v.CellSize != 50 {
+		t.Fatalf("expected 4x2 cells of 50, got %+v", v)
+	}
+	if v.Counts[0] != 2 || v.Counts[v.Cols+3] != 1 {
+		t.Errorf("expected 2 lings in the first cell and 1 in the last, got %v", v.Counts)
+	}
+}
+
+func TestInspect(t *testing.T) {
+	lings := []Ling{{X: 100, Y: 100, VX: 0.5}, {X: 110, Y: 100, VY: 0.5}, {X: 150, Y: 100}}
+	testCases := []struct {
+		desc   string
+		record bool
+	}{
+		{desc: "inspect alone"},
+		{desc: "inspect while recording everything", record: true},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			all := New(append([]Ling(nil), lings...), 500, 500)
+			all.DetectionRadius = 45
+			all.RecordSteering = true
+			all.Update()
+
+			w := New(append([]Ling(nil), lings...), 500, 500)
+			w.DetectionRadius = 45
+			w.RecordSteering = tC.record
+			w.Inspect = 1
+			w.Update()
+			if !tC.record && len(w.Steering) != 0 {
+				t.Errorf("expected no per-ling steering, got %d entries", len(w.Steering))
+			}
+			want, got := all.Steering[1], w.Inspected
+			if got.Total() != want.Total() || len(got.Neighbors) != len(want.Neighbors) {
+				t.Errorf("expected %+v, got %+v", want, got)
+			}
+			if tC.record && len(got.Neighbors) > 0 && &got.Neighbors[0] == &w.Steering[1].Neighbors[0] {
+				t.Error("expected Inspected to have its own neighbor slice")
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package sim
+
+// Trails keeps the last Length positions of every ling in one ring buffer.
+// Every ling gains a point per tick, so they share the write position.
+type Trails struct {
+	length int
+	lings  int
+	// xs and ys hold length points per ling, ling-major.
+	xs, ys []float64
+	head   int
+	count  int
+}
+
+func NewTrails(length int) *Trails {
+	return &Trails{length: max(length, 1)}
+}
+
+func (t *Trails) Length() int {
+	return t.length
+}
+
+// Reset forgets every recorded point, e.g. after lings were moved in a way
+// that shouldn't be drawn as motion. It is safe to call on nil.
+func (t *Trails) Reset() {
+	if t != nil {
+		t.count = 0
+		t.head = 0
+	}
+}
+
+// Record appends the current positions. A change in population restarts
+// the trails, since indices no longer refer to the same lings.
+func (t *Trails) Record(lings []Ling) {
+	if len(lings) != t.lings {
+		t.lings = len(lings)
+		t.xs = make([]float64, t.lings*t.length)
+		t.ys = make([]float64, t.lings*t.length)
+		t.Reset()
+	}
+	for i, l := range lings {
+		t.xs[i*t.length+t.head] = l.X
+		t.ys[i*t.length+t.head] = l.Y
+	}
+	t.head = (t.head + 1) % t.length
+	t.count = min(t.count+1, t.length)
+}
+
+// Tracked is the number of lings in the last Record.
+func (t *Trails) Tracked() int {
+	return t.lings
+}
+
+// Len is the number of points recorded per ling.
+func (t *Trails) Len() int {
+	return t.count
+}
+
+// Point returns ling i's position age ticks ago; age 0 is the newest point
+// and must be below Len.
+func (t *Trails) Point(i, age int) (x, y float64) {
+	j := i*t.length + (t.head-1-age+2*t.length)%t.length
+	return t.xs[j], t.ys[j]
+}
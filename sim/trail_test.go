@@ -0,0 +1,58 @@
+package sim
+
+import "testing"
+
+func TestTrails(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		length int
+		ticks  int
+		want   []float64 // x of ling 1, newest first
+	}{
+		{desc: "partially filled", length: 4, ticks: 2, want: []float64{11, 10}},
+		{desc: "exactly full", length: 3, ticks: 3, want: []float64{12, 11, 10}},
+		{desc: "wrapped keeps the newest", length: 3, ticks: 7, want: []float64{16, 15, 14}},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			tr := NewTrails(tC.length)
+			lings := []Ling{{}, {}}
+			for tick := range tC.ticks {
+				lings[1].X = float64(10 + tick)
+				tr.Record(lings)
+			}
+			if tr.Len() != len(tC.want) {
+				t.Fatalf("expected %d points, got %d", len(tC.want), tr.Len())
+			}
+			for age, want := range tC.want {
+				if x, _ := tr.Point(1, age); x != want {
+					t.Errorf("age %d: expected x %v, got %v", age, want, x)
+				}
+			}
+		})
+	}
+}
+
+func TestTrailsFollowUpdate(t *testing.T) {
+	w := New([]Ling{{X: 50, Y: 50, VX: 1}}, 100, 100)
+	w.Trails = NewTrails(5)
+	for range 3 {
+		w.Update()
+	}
+	if w.Trails.Len() != 3 {
+		t.Fatalf("expected 3 points, got %d", w.Trails.Len())
+	}
+	if x, y := w.Trails.Point(0, 0); x != w.Lings[0].X || y != w.Lings[0].Y {
+		t.Errorf("expected newest point at the ling, got (%v, %v)", x, y)
+	}
+
+	w.Lings = append(w.Lings, Ling{X: 10, Y: 10})
+	w.Update()
+	if w.Trails.Len() != 1 {
+		t.Errorf("expected trails to restart after a population change, got %d points", w.Trails.Len())
+	}
+	w.Resize(200, 200, true)
+	if w.Trails.Len() != 0 {
+		t.Errorf("expected trails to be cleared by a rescaling resize, got %d points", w.Trails.Len())
+	}
+}
@@ -0,0 +1,223 @@
+// Package telemetry exports simulation and runtime metrics in the
+// Prometheus text exposition format, without any client library.
+package telemetry
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"runtime/metrics"
+	"strconv"
+	"swarmlings/sim"
+	"sync"
+	"time"
+)
+
+// updateBuckets are the upper bounds, in seconds, of the update duration
+// histogram.
+var updateBuckets = []float64{0.0001, 0.00025, 0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1}
+
+// gcPauseBuckets re-bucket the runtime's much finer GC pause histogram.
+var gcPauseBuckets = []float64{0.00001, 0.00005, 0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05}
+
+const gcPauseMetric = "/sched/pauses/total/gc:seconds"
+
+// Collector accumulates per-tick measurements from the game loop and
+// renders them on scrape. All methods are safe for concurrent use.
+type Collector struct {
+	mu          sync.Mutex
+	ticks       uint64
+	updateCount []uint64 // per bucket, not cumulative; last entry is +Inf
+	updateSum   float64
+	population  int
+	grid        sim.GridStats
+
+	// Tick rate is measured over windows of at least a second.
+	windowStart time.Time
+	windowTicks int
+	tickRate    float64
+
+	now func() time.Time
+}
+
+func NewCollector() *Collector {
+	return &Collector{updateCount: make([]uint64, len(updateBuckets)+1), now: time.Now}
+}
+
+// ObserveUpdate records one World.Update that took d.
+func (c *Collector) ObserveUpdate(d time.Duration, w *sim.World) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ticks++
+	secs := d.Seconds()
+	c.updateSum += secs
+	i := 0
+	for i < len(updateBuckets) && secs > updateBuckets[i] {
+		i++
+	}
+	c.updateCount[i]++
+	c.population = len(w.Lings)
+	c.grid = w.GridStats()
+
+	now := c.now()
+	if c.windowStart.IsZero() {
+		c.windowStart = now
+	}
+	c.windowTicks++
+	c.closeWindow(now)
+}
+
+// closeWindow updates the tick rate once the window spans a second. Scrapes
+// call it too, so a paused or stalled sim drops to 0 instead of reporting
+// its last rate forever.
+func (c *Collector) closeWindow(now time.Time) {
+	if c.windowStart.IsZero() {
+		return
+	}
+	if elapsed := now.Sub(c.windowStart); elapsed >= time.Second {
+		c.tickRate = float64(c.windowTicks) / elapsed.Seconds()
+		c.windowStart = now
+		c.windowTicks = 0
+	}
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+type writer struct {
+	bw *bufio.Writer
+}
+
+func (w writer) header(name, typ, help string) {
+	fmt.Fprintf(w.bw, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+func (w writer) sample(name string, v float64) {
+	fmt.Fprintf(w.bw, "%s %s\n", name, formatFloat(v))
+}
+
+func (w writer) gauge(name, help string, v float64) {
+	w.header(name, "gauge", help)
+	w.sample(name, v)
+}
+
+// histogram writes cumulative buckets from per-bucket counts; counts has one
+// more entry than bounds for the +Inf bucket.
+func (w writer) histogram(name, help string, bounds []float64, counts []uint64, sum float64) {
+	w.header(name, "histogram", help)
+	var cum uint64
+	for i, c := range counts {
+		cum += c
+		le := math.Inf(1)
+		if i < len(bounds) {
+			le = bounds[i]
+		}
+		fmt.Fprintf(w.bw, "%s_bucket{le=\"%s\"} %d\n", name, formatFloat(le), cum)
+	}
+	fmt.Fprintf(w.bw, "%s_sum %s\n%s_count %d\n", name, formatFloat(sum), name, cum)
+}
+
+// WriteTo renders every metric in the Prometheus text format.
+func (c *Collector) WriteTo(out io.Writer) (int64, error) {
+	c.mu.Lock()
+	c.closeWindow(c.now())
+	ticks := c.ticks
+	counts := append([]uint64(nil), c.updateCount...)
+	sum := c.updateSum
+	population := c.population
+	grid := c.grid
+	rate := c.tickRate
+	c.mu.Unlock()
+
+	cw := &countingWriter{w: out}
+	w := writer{bufio.NewWriter(cw)}
+	w.header("swarmlings_ticks_total", "counter", "Simulation ticks since start.")
+	w.sample("swarmlings_ticks_total", float64(ticks))
+	w.gauge("swarmlings_tick_rate", "Simulation ticks per second over the last measurement window.", rate)
+	w.histogram("swarmlings_update_duration_seconds", "Time spent in World.Update per tick.", updateBuckets, counts, sum)
+	w.gauge("swarmlings_population", "Number of lings in the world.", float64(population))
+	w.gauge("swarmlings_grid_cells", "Number of spatial grid cells.", float64(grid.Cells))
+	w.gauge("swarmlings_grid_empty_cells", "Number of spatial grid cells holding no lings.", float64(grid.Empty))
+	w.gauge("swarmlings_grid_max_occupancy", "Most lings in a single grid cell.", float64(grid.Max))
+	w.gauge("swarmlings_grid_mean_occupancy", "Mean lings per non-empty grid cell.", grid.MeanOccupied)
+	writeGCPauses(w)
+	if err := w.bw.Flush(); err != nil {
+		return cw.n, err
+	}
+	return cw.n, nil
+}
+
+// writeGCPauses folds the runtime's GC pause histogram into gcPauseBuckets.
+// Each runtime bucket is counted under the first bound at or above its
+// upper edge, which can only overstate a pause.
+func writeGCPauses(w writer) {
+	s := []metrics.Sample{{Name: gcPauseMetric}}
+	metrics.Read(s)
+	if s[0].Value.Kind() != metrics.KindFloat64Histogram {
+		return
+	}
+	h := s[0].Value.Float64Histogram()
+	counts := make([]uint64, len(gcPauseBuckets)+1)
+	var sum float64
+	for i, n := range h.Counts {
+		if n == 0 {
+			continue
+		}
+		lo, hi := h.Buckets[i], h.Buckets[i+1]
+		j := 0
+		for j < len(gcPauseBuckets) && hi > gcPauseBuckets[j] {
+			j++
+		}
+		counts[j] += n
+		// Estimate the sum from bucket midpoints; open-ended buckets use
+		// their finite edge.
+		mid := (lo + hi) / 2
+		if math.IsInf(lo, -1) {
+			mid = hi
+		} else if math.IsInf(hi, 1) {
+			mid = lo
+		}
+		sum += mid * float64(n)
+	}
+	w.histogram("go_gc_pause_seconds", "Stop-the-world GC pause durations (sum estimated from bucket midpoints).", gcPauseBuckets, counts, sum)
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	c.WriteTo(w)
+}
+
+// Serve exposes the collector at /metrics on addr in the background and
+// returns the address it listens on.
+func (c *Collector) Serve(addr string) (net.Addr, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", c)
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go srv.Serve(ln)
+	return ln.Addr(), nil
+}
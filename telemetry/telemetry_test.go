@@ -0,0 +1,94 @@
+package telemetry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"swarmlings/sim"
+	"testing"
+	"time"
+)
+
+func TestCollectorOutput(t *testing.T) {
+	c := NewCollector()
+	now := time.Unix(0, 0)
+	c.now = func() time.Time { return now }
+	world := sim.New([]sim.Ling{{X: 10, Y: 10}, {X: 12, Y: 10}, {X: 90, Y: 90}}, 100, 100)
+	world.DetectionRadius = 50
+	world.Update()
+	for _, d := range []time.Duration{200 * time.Microsecond, 3 * time.Millisecond, time.Second} {
+		c.ObserveUpdate(d, &world)
+		now = now.Add(500 * time.Millisecond)
+	}
+
+	var sb strings.Builder
+	if _, err := c.WriteTo(&sb); err != nil {
+		t.Fatal(err)
+	}
+	out := sb.String()
+	testCases := []struct {
+		desc string
+		line string
+	}{
+		{desc: "tick counter", line: "swarmlings_ticks_total 3"},
+		{desc: "tick rate over the first full window", line: "swarmlings_tick_rate 3"},
+		{desc: "fast update lands in its bucket", line: `swarmlings_update_duration_seconds_bucket{le="0.00025"} 1`},
+		{desc: "buckets are cumulative", line: `swarmlings_update_duration_seconds_bucket{le="0.005"} 2`},
+		{desc: "slow update only in +Inf", line: `swarmlings_update_duration_seconds_bucket{le="+Inf"} 3`},
+		{desc: "histogram count", line: "swarmlings_update_duration_seconds_count 3"},
+		{desc: "population", line: "swarmlings_population 3"},
+		{desc: "two occupied cells", line: "swarmlings_grid_mean_occupancy 1.5"},
+		{desc: "busiest cell", line: "swarmlings_grid_max_occupancy 2"},
+		{desc: "gc pauses", line: "# TYPE go_gc_pause_seconds histogram"},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			if !strings.Contains(out, tC.line+"\n") {
+				t.Errorf("expected line %q in:\n%s", tC.line, out)
+			}
+		})
+	}
+}
+
+func TestTickRateStall(t *testing.T) {
+	c := NewCollector()
+	now := time.Unix(0, 0)
+	c.now = func() time.Time { return now }
+	world := sim.New(nil, 100, 100)
+	for range 5 {
+		c.ObserveUpdate(time.Millisecond, &world)
+		now = now.Add(250 * time.Millisecond)
+	}
+	testCases := []struct {
+		desc  string
+		after time.Duration
+		line  string
+	}{
+		{desc: "running", after: 0, line: "swarmlings_tick_rate 5"},
+		{desc: "window still open", after: 500 * time.Millisecond, line: "swarmlings_tick_rate 5"},
+		{desc: "stalled", after: time.Second, line: "swarmlings_tick_rate 0"},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			now = now.Add(tC.after)
+			var sb strings.Builder
+			if _, err := c.WriteTo(&sb); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(sb.String(), tC.line+"\n") {
+				t.Errorf("expected line %q in:\n%s", tC.line, sb.String())
+			}
+		})
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewCollector().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("expected Prometheus text content type, got %q", ct)
+	}
+	if !strings.Contains(rec.Body.String(), "swarmlings_ticks_total 0\n") {
+		t.Errorf("expected zero tick counter, got:\n%s", rec.Body.String())
+	}
+}